@@ -1,5 +1,10 @@
 package quill
 
+import (
+	"io"
+	"strings"
+)
+
 // paragraph
 type textFormat struct{}
 
@@ -32,7 +37,7 @@ func (*blockQuoteFormat) HasFormat(o *Op) bool {
 
 // header
 type headerFormat struct {
-	level string // the string "1", "2", "3", ...
+	level string // the string "1", "2", "3", "4", "5", or "6"
 }
 
 func (hf *headerFormat) Fmt() *Format {
@@ -47,6 +52,16 @@ func (hf *headerFormat) HasFormat(o *Op) bool {
 	return o.Attrs["header"] == hf.level
 }
 
+// headerLevels lists the values of the header attribute, since only h1 to h6 exist in HTML.
+var headerLevels = map[string]bool{
+	"1": true,
+	"2": true,
+	"3": true,
+	"4": true,
+	"5": true,
+	"6": true,
+}
+
 // list
 type listFormat struct {
 	lType  string // either "ul" or "ol"
@@ -88,23 +103,12 @@ func (lf *listFormat) Close(open []*Format, o *Op, doingBlock bool) bool {
 		return false
 	}
 
-	t := o.Attrs["list"] // The type of the current list item (ordered or bullet).
-
-	return !o.HasAttr("list") || (t == "ordered" && lf.lType != "ol") || (t == "bullet" && lf.lType != "ul")
+	// Like Quill.js, indented items are written as siblings with an indent class. WithNestedLists gives truly nested
+	// lists instead.
 
-	// Currently, the way Quill.js renders nested lists isn't very satisfactory. But we'll stay consistent with how
-	// it appears to users for now. The code below is mostly correct for a better way to render nested lists.
+	t := o.Attrs["list"] // The type of the current list item (bullet, or else ordered).
 
-	//if !o.HasAttr("list") { // If the block is not a list item at all, close the list block.
-	//	return true
-	//}
-
-	//t := o.Attrs["list"]                   // The type of the current list item (ordered or bullet).
-	// ind := indentDepths[o.Attrs["indent"]] // The indent of the current list item.
-
-	// Close the list block only if both (a) the current list item is staying at the same indent level or is at a
-	// lower indent level and (b) the type of the list is different from the type of the previous.
-	// return ind <= lf.indent && ((t == "ordered" && lf.lType != "ol") || (t == "bullet" && lf.lType != "ul"))
+	return !o.HasAttr("list") || (t != "bullet" && lf.lType != "ol") || (t == "bullet" && lf.lType != "ul")
 
 }
 
@@ -119,12 +123,21 @@ var indentDepths = map[string]uint8{
 
 // text alignment
 type alignFormat struct {
-	val string
+	val   string
+	class string // the classes of the alignment
+	style string // the text-align written in place of the class, if inline styles are used
 }
 
 func (af *alignFormat) Fmt() *Format {
+	if af.style != "" {
+		return &Format{
+			Val:   "text-align:" + af.style + ";",
+			Place: Style,
+			Block: true,
+		}
+	}
 	return &Format{
-		Val:   "ql-align-" + af.val,
+		Val:   af.class,
 		Place: Class,
 		Block: true,
 	}
@@ -134,13 +147,54 @@ func (af *alignFormat) HasFormat(o *Op) bool {
 	return o.Attrs["align"] == af.val
 }
 
+// quillAligns lists the values of the align attribute that Quill allows; text is aligned left without the attribute.
+var quillAligns = map[string]bool{
+	"center":  true,
+	"right":   true,
+	"justify": true,
+}
+
+// text direction (only right-to-left can be set)
+type directionFormat struct {
+	class string // the classes of the direction
+	style string // the direction written in place of the class, if inline styles are used
+}
+
+func (df *directionFormat) Fmt() *Format {
+	if df.style != "" {
+		return &Format{
+			Val:   df.style,
+			Place: Style,
+			Block: true,
+		}
+	}
+	return &Format{
+		Val:   df.class,
+		Place: Class,
+		Block: true,
+	}
+}
+
+func (*directionFormat) HasFormat(o *Op) bool {
+	return o.Attrs["direction"] == "rtl"
+}
+
 type indentFormat struct {
-	in string
+	in    string
+	class string // the classes of the indent level
+	style string // the padding declaration written in place of the class, if inline styles are used
 }
 
 func (inf *indentFormat) Fmt() *Format {
+	if inf.style != "" {
+		return &Format{
+			Val:   inf.style,
+			Place: Style,
+			Block: true,
+		}
+	}
 	return &Format{
-		Val:   "indent-" + inf.in,
+		Val:   inf.class,
 		Place: Class,
 		Block: true,
 	}
@@ -150,9 +204,23 @@ func (inf *indentFormat) HasFormat(o *Op) bool {
 	return o.Attrs["indent"] == inf.in
 }
 
+// quillIndents lists the values of the indent attribute that Quill allows, up to eight levels.
+var quillIndents = map[string]bool{
+	"1": true,
+	"2": true,
+	"3": true,
+	"4": true,
+	"5": true,
+	"6": true,
+	"7": true,
+	"8": true,
+}
+
 // code block
 type codeBlockFormat struct {
-	o *Op
+	o    *Op
+	pre  string // the opening wrap if not "<pre>"
+	lang string // the language of the code block, if code blocks in other languages are separate pre elements
 }
 
 func (cf *codeBlockFormat) Fmt() *Format {
@@ -167,7 +235,10 @@ func (cf *codeBlockFormat) HasFormat(o *Op) bool {
 }
 
 // codeBlockFormat implements the FormatWrapper interface.
-func (*codeBlockFormat) Wrap() (string, string) {
+func (cf *codeBlockFormat) Wrap() (string, string) {
+	if cf.pre != "" {
+		return cf.pre, "\n</pre>"
+	}
 	return "<pre>", "\n</pre>"
 }
 
@@ -175,7 +246,7 @@ func (*codeBlockFormat) Wrap() (string, string) {
 func (*codeBlockFormat) Open(open []*Format, _ *Op) bool {
 	// If there is a code block already open, no need to open another.
 	for i := range open {
-		if open[i].Place == Tag && open[i].Val == "<pre>" {
+		if _, ok := open[i].fm.(*codeBlockFormat); ok && open[i].wrap {
 			return false
 		}
 	}
@@ -184,13 +255,67 @@ func (*codeBlockFormat) Open(open []*Format, _ *Op) bool {
 
 // codeBlockFormat implements the FormatWrapper interface.
 func (cf *codeBlockFormat) Close(_ []*Format, o *Op, doingBlock bool) bool {
-	if doingBlock && !o.HasAttr("code-block") {
+	if !doingBlock {
+		return false
+	}
+	if !o.HasAttr("code-block") {
 		return true
 	}
-	// We are simply adding another line to the code block. The previous line should end with
-	// a "\n" though all instances of "\n" are stripped out by the split from the start.
-	if !doingBlock {
-		o.Data = "\n" + o.Data
+	if (cf.pre != "" && cf.pre != quill2Pre(o.Attrs["code-block"])) || (cf.lang != "" && cf.lang != o.Attrs["code-block"]) {
+		return true // A code block in another language is a separate pre element.
 	}
+	// We are simply adding another line to the code block, so it must be separated from the previous line.
+	o.Data = "\n" + o.Data
 	return false
 }
+
+// divider
+type dividerFormat struct {
+	classes, styles []string // those of the line of the divider
+}
+
+func (*dividerFormat) Fmt() *Format { return nil } // The body contains the entire element.
+
+func (*dividerFormat) HasFormat(o *Op) bool {
+	return o.Type == "divider"
+}
+
+// dividerFormat implements the FormatWriter interface.
+func (df *dividerFormat) Write(buf io.Writer) {
+	io.WriteString(buf, "<hr"+styleAttrs(df.classes, df.styles)+">")
+}
+
+// dividerFormat implements the embedStyler interface.
+func (df *dividerFormat) setStyles(classes, styles []string) {
+	df.classes, df.styles = classes, styles
+}
+
+// dividerFormat implements the BlockEmbed interface.
+func (*dividerFormat) IsBlock() bool { return true }
+
+// page break
+type pageBreakFormat struct {
+	class           string   // the classes of the break, if any
+	classes, styles []string // those of the line of the break
+}
+
+func (*pageBreakFormat) Fmt() *Format { return nil } // The body contains the entire element.
+
+func (*pageBreakFormat) HasFormat(o *Op) bool {
+	return o.Type == "page-break"
+}
+
+// pageBreakFormat implements the FormatWriter interface. The break is an empty element that printing breaks the page
+// after.
+func (pf *pageBreakFormat) Write(buf io.Writer) {
+	styles := append(pf.styles[:len(pf.styles):len(pf.styles)], "break-after:page;page-break-after:always")
+	io.WriteString(buf, `<div`+styleAttrs(append(strings.Fields(pf.class), pf.classes...), styles)+`></div>`)
+}
+
+// pageBreakFormat implements the embedStyler interface.
+func (pf *pageBreakFormat) setStyles(classes, styles []string) {
+	pf.classes, pf.styles = classes, styles
+}
+
+// pageBreakFormat implements the BlockEmbed interface.
+func (*pageBreakFormat) IsBlock() bool { return true }
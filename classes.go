@@ -0,0 +1,109 @@
+package quill
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A ClassMapper gives the class names written for a format with a value, such as "size" and "huge", "align" and
+// "center", "indent" and "2", "font" and "serif", "direction" and "rtl", or "page-break" and "y". It may return no
+// classes to leave the format out (a page break is still written, without a class). A ClassMapper must be safe for
+// concurrent use.
+type ClassMapper func(format, value string) []string
+
+// WithClassMapper sets the ClassMapper that names the classes of the size, align, indent, font, direction and
+// page-break formats. Without one, the classes of Quill are written (see QuillClasses), except that indents have the
+// indent-N classes of Quill 1. When inline styles are set with WithInlineStyles, no classes are written for these
+// formats.
+func WithClassMapper(m ClassMapper) Option {
+	return func(r *Renderer) {
+		r.classes = m
+	}
+}
+
+// QuillClasses gives the classes of Quill's stylesheet: ql-size-*, ql-align-*, ql-indent-*, ql-font-*,
+// ql-direction-rtl and ql-page-break. Only the fonts that Quill offers are given classes.
+func QuillClasses(format, value string) []string {
+	switch {
+	case format == "font" && !quillFonts[value]:
+		return nil
+	case format == "page-break":
+		return []string{"ql-page-break"}
+	}
+	return []string{"ql-" + format + "-" + value}
+}
+
+// TailwindClasses gives the utility classes of Tailwind CSS that come closest to the default theme of Quill: a text-*
+// size, a text-* alignment, a pl-* padding of 3rem for each indent level, the font-serif or font-mono family, a
+// [direction:rtl] direction, and a break-after-page page break.
+func TailwindClasses(format, value string) []string {
+	var c string
+	switch format {
+	case "size":
+		c = map[string]string{"small": "text-xs", "large": "text-2xl", "huge": "text-4xl"}[value]
+	case "align":
+		if value == "center" || value == "right" || value == "justify" {
+			c = "text-" + value
+		}
+	case "indent":
+		if n, err := strconv.Atoi(value); err == nil && n > 0 && n <= 8 {
+			if n <= 6 {
+				c = "pl-" + strconv.Itoa(12*n) // The spacing scale of Tailwind goes by fourths of a rem.
+			} else {
+				c = "pl-[" + strconv.Itoa(3*n) + "rem]"
+			}
+		}
+	case "font":
+		c = map[string]string{"serif": "font-serif", "monospace": "font-mono"}[value]
+	case "direction":
+		if value == "rtl" {
+			c = "[direction:rtl]"
+		}
+	case "page-break":
+		c = "break-after-page"
+	}
+	return classList(c)
+}
+
+// BootstrapClasses gives the utility classes of Bootstrap 5 that come closest to the default theme of Quill: the small
+// class or an fs-* size, a text-center or text-end alignment, a ps-5 padding for the first indent level, and the
+// font-monospace family. Bootstrap has no classes for justified text, deeper indents, serif fonts, text direction or
+// page breaks, so these are left out.
+func BootstrapClasses(format, value string) []string {
+	var c string
+	switch format {
+	case "size":
+		c = map[string]string{"small": "small", "large": "fs-4", "huge": "fs-1"}[value]
+	case "align":
+		c = map[string]string{"center": "text-center", "right": "text-end"}[value]
+	case "indent":
+		if value == "1" {
+			c = "ps-5"
+		}
+	case "font":
+		if value == "monospace" {
+			c = "font-monospace"
+		}
+	}
+	return classList(c)
+}
+
+// classList returns c as a list of classes, which is empty if c is blank.
+func classList(c string) []string {
+	if c == "" {
+		return nil
+	}
+	return []string{c}
+}
+
+// className returns the value of the class attribute for the format with the value, or "" if the format is not to be
+// written.
+func (r *Renderer) className(format, value string) string {
+	if r.classes != nil {
+		return strings.Join(r.classes(format, value), " ")
+	}
+	if format == "indent" && r.version != Quill2 {
+		return "indent-" + value
+	}
+	return strings.Join(QuillClasses(format, value), " ")
+}
@@ -0,0 +1,102 @@
+package quill
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassMapper(t *testing.T) {
+
+	ops := `[{"insert":"a","attributes":{"size":"huge","font":"monospace"}},{"insert":"\n","attributes":{"align":"center","indent":2}},
+		{"insert":"b","attributes":{"size":"small","font":"serif"}},{"insert":"\n","attributes":{"align":"justify","indent":1}}]`
+
+	cases := map[string]struct {
+		opts []Option
+		want string
+	}{
+		"default": {
+			want: `<p class="indent-2 ql-align-center"><span class="ql-font-monospace"><span class="ql-size-huge">a</span></span></p>` +
+				`<p class="indent-1 ql-align-justify"><span class="ql-font-serif"><span class="ql-size-small">b</span></span></p>`,
+		},
+		"quill": {
+			opts: []Option{WithClassMapper(QuillClasses)},
+			want: `<p class="ql-align-center ql-indent-2"><span class="ql-font-monospace"><span class="ql-size-huge">a</span></span></p>` +
+				`<p class="ql-align-justify ql-indent-1"><span class="ql-font-serif"><span class="ql-size-small">b</span></span></p>`,
+		},
+		"tailwind": {
+			opts: []Option{WithClassMapper(TailwindClasses)},
+			want: `<p class="pl-24 text-center"><span class="font-mono"><span class="text-4xl">a</span></span></p>` +
+				`<p class="pl-12 text-justify"><span class="font-serif"><span class="text-xs">b</span></span></p>`,
+		},
+		"bootstrap": {
+			opts: []Option{WithClassMapper(BootstrapClasses)},
+			want: `<p class="text-center"><span class="font-monospace"><span class="fs-1">a</span></span></p>` +
+				`<p class="ps-5"><span class="small">b</span></p>`,
+		},
+		"several classes": {
+			opts: []Option{WithClassMapper(func(format, value string) []string {
+				return []string{"my-" + format, "my-" + format + "-" + value}
+			})},
+			want: `<p class="my-align my-align-center my-indent my-indent-2"><span class="my-font my-font-monospace"><span class="my-size my-size-huge">a</span></span></p>` +
+				`<p class="my-align my-align-justify my-indent my-indent-1"><span class="my-font my-font-serif"><span class="my-size my-size-small">b</span></span></p>`,
+		},
+		"inline styles win": {
+			opts: []Option{WithClassMapper(TailwindClasses), WithInlineStyles(nil)},
+			want: `<p style="padding-left:6em;text-align:center;"><span style="font-family:Monaco, Courier New, monospace;"><span style="font-size:2.5em;">a</span></span></p>` +
+				`<p style="padding-left:3em;text-align:justify;"><span style="font-family:Georgia, Times New Roman, serif;"><span style="font-size:0.75em;">b</span></span></p>`,
+		},
+	}
+
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			got, err := NewRenderer(tc.opts...).Render([]byte(ops))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("bad rendering\ngot:  %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+
+}
+
+func TestTailwindClasses(t *testing.T) {
+	cases := []struct {
+		format, value string
+		want          []string
+	}{
+		{"indent", "6", []string{"pl-72"}},
+		{"indent", "8", []string{"pl-[24rem]"}},
+		{"indent", "9", nil},
+		{"align", "left", nil},
+		{"size", "giant", nil},
+		{"font", "cursive", nil},
+		{"direction", "rtl", []string{"[direction:rtl]"}},
+		{"direction", "ltr", nil},
+		{"page-break", "y", []string{"break-after-page"}},
+	}
+	for _, tc := range cases {
+		if got := TailwindClasses(tc.format, tc.value); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s %s: got %v", tc.format, tc.value, got)
+		}
+	}
+
+	ops := `[{"insert":"a"},{"insert":"\n","attributes":{"direction":"rtl","align":"right"}},{"insert":{"page-break":true}},{"insert":"\n"}]`
+	want := `<p class="[direction:rtl] text-right">a</p><div class="break-after-page" style="break-after:page;page-break-after:always"></div>`
+	got, err := NewRenderer(WithClassMapper(TailwindClasses)).Render([]byte(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("bad rendering; got: %s", got)
+	}
+
+	got, err = NewRenderer(WithClassMapper(BootstrapClasses)).Render([]byte(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<p class="text-end">a</p><div style="break-after:page;page-break-after:always"></div>`; string(got) != want {
+		t.Errorf("bad rendering with Bootstrap; got: %s", got)
+	}
+}
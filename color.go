@@ -0,0 +1,487 @@
+package quill
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A Color is a CSS color value in the sRGB color space.
+type Color struct {
+	R, G, B uint8
+	A       float64 // the opacity, from 0 (transparent) to 1 (opaque)
+}
+
+// String gives the canonical form of the color: "#rrggbb" if the color is opaque or "rgba(r,g,b,a)" otherwise.
+func (c Color) String() string {
+	if c.A >= 1 {
+		const hex = "0123456789abcdef"
+		return string([]byte{'#', hex[c.R>>4], hex[c.R&0xf], hex[c.G>>4], hex[c.G&0xf], hex[c.B>>4], hex[c.B&0xf]})
+	}
+	return "rgba(" + strconv.Itoa(int(c.R)) + "," + strconv.Itoa(int(c.G)) + "," + strconv.Itoa(int(c.B)) + "," +
+		strconv.FormatFloat(c.A, 'f', -1, 64) + ")"
+}
+
+var errBadColor = errors.New("quill: invalid CSS color")
+
+// ParseColor parses a CSS color given as a hex value ("#rgb", "#rgba", "#rrggbb" or "#rrggbbaa"), an rgb() or rgba()
+// function, an hsl() or hsla() function, or a named color (including "transparent"). Both the comma-separated and the
+// space-separated syntax of the functions is accepted.
+func ParseColor(s string) (Color, error) {
+
+	s = strings.ToLower(strings.TrimSpace(s))
+
+	if strings.HasPrefix(s, "#") {
+		return parseHexColor(s[1:])
+	}
+
+	if i := strings.IndexByte(s, '('); i > 0 && strings.HasSuffix(s, ")") {
+		name, args := strings.TrimSpace(s[:i]), splitColorArgs(s[i+1:len(s)-1])
+		if args == nil {
+			return Color{}, errBadColor
+		}
+		switch name {
+		case "rgb", "rgba":
+			return rgbColor(args)
+		case "hsl", "hsla":
+			return hslColor(args)
+		}
+		return Color{}, errBadColor
+	}
+
+	if c, ok := namedColors[s]; ok {
+		return c, nil
+	}
+
+	return Color{}, errBadColor
+
+}
+
+// parseHexColor parses the hex digits of a color (without the "#").
+func parseHexColor(h string) (Color, error) {
+
+	var digits [8]uint8
+	for i := 0; i < len(h); i++ {
+		if i == len(digits) {
+			return Color{}, errBadColor
+		}
+		switch d := h[i]; {
+		case d >= '0' && d <= '9':
+			digits[i] = d - '0'
+		case d >= 'a' && d <= 'f':
+			digits[i] = d - 'a' + 10
+		default:
+			return Color{}, errBadColor
+		}
+	}
+
+	c := Color{A: 1}
+	switch len(h) {
+	case 3, 4:
+		c.R, c.G, c.B = digits[0]*17, digits[1]*17, digits[2]*17
+		if len(h) == 4 {
+			c.A = roundAlpha(float64(digits[3]*17) / 255)
+		}
+	case 6, 8:
+		c.R, c.G, c.B = digits[0]<<4|digits[1], digits[2]<<4|digits[3], digits[4]<<4|digits[5]
+		if len(h) == 8 {
+			c.A = roundAlpha(float64(digits[6]<<4|digits[7]) / 255)
+		}
+	default:
+		return Color{}, errBadColor
+	}
+	return c, nil
+
+}
+
+// splitColorArgs splits the arguments of a color function, which are either all separated by commas or all separated by
+// spaces with the alpha value optionally following a "/". The alpha value, if present, is always the fourth element.
+func splitColorArgs(s string) []string {
+
+	var args []string
+	if strings.IndexByte(s, ',') != -1 {
+		args = strings.Split(s, ",")
+		for i := range args {
+			args[i] = strings.TrimSpace(args[i])
+		}
+	} else {
+		alpha := ""
+		if i := strings.IndexByte(s, '/'); i != -1 {
+			alpha = strings.TrimSpace(s[i+1:])
+			s = s[:i]
+			if alpha == "" {
+				return nil
+			}
+		}
+		args = strings.Fields(s)
+		if alpha != "" {
+			if len(args) != 3 {
+				return nil
+			}
+			args = append(args, alpha)
+		}
+	}
+
+	if len(args) != 3 && len(args) != 4 {
+		return nil
+	}
+	for i := range args {
+		if args[i] == "" {
+			return nil
+		}
+	}
+	return args
+
+}
+
+// rgbColor makes a color from the arguments of an rgb() or rgba() function.
+func rgbColor(args []string) (Color, error) {
+	var rgb [3]uint8
+	for i := 0; i < 3; i++ {
+		var v float64
+		var err error
+		if strings.HasSuffix(args[i], "%") {
+			v, err = parseColorNumber(args[i][:len(args[i])-1])
+			v = v * 255 / 100
+		} else {
+			v, err = parseColorNumber(args[i])
+		}
+		if err != nil {
+			return Color{}, err
+		}
+		rgb[i] = clampByte(v)
+	}
+	a, err := colorAlpha(args)
+	if err != nil {
+		return Color{}, err
+	}
+	return Color{rgb[0], rgb[1], rgb[2], a}, nil
+}
+
+// hslColor makes a color from the arguments of an hsl() or hsla() function.
+func hslColor(args []string) (Color, error) {
+
+	h, err := parseHue(args[0])
+	if err != nil {
+		return Color{}, err
+	}
+
+	var sl [2]float64
+	for i := range sl {
+		if !strings.HasSuffix(args[i+1], "%") {
+			return Color{}, errBadColor
+		}
+		v, err := parseColorNumber(args[i+1][:len(args[i+1])-1])
+		if err != nil {
+			return Color{}, err
+		}
+		sl[i] = math.Max(0, math.Min(100, v)) / 100
+	}
+
+	a, err := colorAlpha(args)
+	if err != nil {
+		return Color{}, err
+	}
+
+	// Convert as described in CSS Color Module Level 3.
+	s, l := sl[0], sl[1]
+	var m2 float64
+	if l <= 0.5 {
+		m2 = l * (s + 1)
+	} else {
+		m2 = l + s - l*s
+	}
+	m1 := l*2 - m2
+	h = h / 360
+
+	return Color{
+		R: clampByte(hueToRGB(m1, m2, h+1.0/3) * 255),
+		G: clampByte(hueToRGB(m1, m2, h) * 255),
+		B: clampByte(hueToRGB(m1, m2, h-1.0/3) * 255),
+		A: a,
+	}, nil
+
+}
+
+func hueToRGB(m1, m2, h float64) float64 {
+	if h < 0 {
+		h++
+	} else if h > 1 {
+		h--
+	}
+	switch {
+	case h*6 < 1:
+		return m1 + (m2-m1)*h*6
+	case h*2 < 1:
+		return m2
+	case h*3 < 2:
+		return m1 + (m2-m1)*(2.0/3-h)*6
+	}
+	return m1
+}
+
+// parseHue parses a hue angle and returns it in degrees in the range [0, 360).
+func parseHue(s string) (float64, error) {
+	unit := 1.0
+	for _, u := range []struct {
+		suffix string
+		deg    float64
+	}{{"deg", 1}, {"grad", 0.9}, {"rad", 180 / math.Pi}, {"turn", 360}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = s[:len(s)-len(u.suffix)], u.deg
+			break
+		}
+	}
+	v, err := parseColorNumber(s)
+	if err != nil {
+		return 0, err
+	}
+	v = math.Mod(v*unit, 360)
+	if v < 0 {
+		v += 360
+	}
+	return v, nil
+}
+
+// colorAlpha gives the alpha value from the fourth argument of a color function, if there is one.
+func colorAlpha(args []string) (float64, error) {
+	if len(args) < 4 {
+		return 1, nil
+	}
+	s := args[3]
+	percent := strings.HasSuffix(s, "%")
+	if percent {
+		s = s[:len(s)-1]
+	}
+	v, err := parseColorNumber(s)
+	if err != nil {
+		return 0, err
+	}
+	if percent {
+		v /= 100
+	}
+	return roundAlpha(math.Max(0, math.Min(1, v))), nil
+}
+
+// parseColorNumber parses a plain CSS number.
+func parseColorNumber(s string) (float64, error) {
+	if s == "" || strings.ContainsAny(s, "xXpPnN_") { // Exclude the hex, Inf, NaN, and underscore forms that Go accepts.
+		return 0, errBadColor
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errBadColor
+	}
+	return v, nil
+}
+
+func clampByte(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(255, v))))
+}
+
+// roundAlpha rounds an alpha value to three decimal places.
+func roundAlpha(a float64) float64 {
+	return math.Round(a*1000) / 1000
+}
+
+// A ColorPolicy decides which values of the color and background attributes are written out. The zero value allows
+// any valid color. Colors are always written in their canonical form (see Color.String).
+type ColorPolicy struct {
+	palette map[Color]bool
+}
+
+// NewColorPolicy returns a ColorPolicy that allows only the colors in the palette, or any valid color if the palette
+// is empty. An error is returned if any color in the palette cannot be parsed.
+func NewColorPolicy(palette ...string) (*ColorPolicy, error) {
+	p := new(ColorPolicy)
+	if len(palette) == 0 {
+		return p, nil
+	}
+	p.palette = make(map[Color]bool, len(palette))
+	for _, s := range palette {
+		c, err := ParseColor(s)
+		if err != nil {
+			return nil, errors.New("quill: invalid color in palette: " + strconv.Quote(s))
+		}
+		p.palette[c] = true
+	}
+	return p, nil
+}
+
+// Color checks the raw color value of an attribute against the policy. If the color is allowed, Color returns its
+// canonical form and true. Otherwise, it returns false.
+func (p *ColorPolicy) Color(raw string) (string, bool) {
+	c, err := ParseColor(raw)
+	if err != nil || (p.palette != nil && !p.palette[c]) {
+		return "", false
+	}
+	return c.String(), true
+}
+
+// Formatter gives the formats for the color and background attributes that follow the policy. Values not allowed by
+// the policy are dropped. Formatter can be given to RenderExtended as the function providing custom formats, though
+// usually the policy is set on a Renderer with WithColorPolicy.
+func (p *ColorPolicy) Formatter(keyword string, o *Op) Formatter {
+	switch keyword {
+	case "color", "background":
+		if fmTer := newColorFormat(keyword, o, p); fmTer != nil {
+			return fmTer
+		}
+		return noFormat{}
+	}
+	return nil
+}
+
+// namedColors holds the CSS named colors.
+var namedColors = map[string]Color{
+	"transparent":          {0, 0, 0, 0},
+	"aliceblue":            {240, 248, 255, 1},
+	"antiquewhite":         {250, 235, 215, 1},
+	"aqua":                 {0, 255, 255, 1},
+	"aquamarine":           {127, 255, 212, 1},
+	"azure":                {240, 255, 255, 1},
+	"beige":                {245, 245, 220, 1},
+	"bisque":               {255, 228, 196, 1},
+	"black":                {0, 0, 0, 1},
+	"blanchedalmond":       {255, 235, 205, 1},
+	"blue":                 {0, 0, 255, 1},
+	"blueviolet":           {138, 43, 226, 1},
+	"brown":                {165, 42, 42, 1},
+	"burlywood":            {222, 184, 135, 1},
+	"cadetblue":            {95, 158, 160, 1},
+	"chartreuse":           {127, 255, 0, 1},
+	"chocolate":            {210, 105, 30, 1},
+	"coral":                {255, 127, 80, 1},
+	"cornflowerblue":       {100, 149, 237, 1},
+	"cornsilk":             {255, 248, 220, 1},
+	"crimson":              {220, 20, 60, 1},
+	"cyan":                 {0, 255, 255, 1},
+	"darkblue":             {0, 0, 139, 1},
+	"darkcyan":             {0, 139, 139, 1},
+	"darkgoldenrod":        {184, 134, 11, 1},
+	"darkgray":             {169, 169, 169, 1},
+	"darkgreen":            {0, 100, 0, 1},
+	"darkgrey":             {169, 169, 169, 1},
+	"darkkhaki":            {189, 183, 107, 1},
+	"darkmagenta":          {139, 0, 139, 1},
+	"darkolivegreen":       {85, 107, 47, 1},
+	"darkorange":           {255, 140, 0, 1},
+	"darkorchid":           {153, 50, 204, 1},
+	"darkred":              {139, 0, 0, 1},
+	"darksalmon":           {233, 150, 122, 1},
+	"darkseagreen":         {143, 188, 143, 1},
+	"darkslateblue":        {72, 61, 139, 1},
+	"darkslategray":        {47, 79, 79, 1},
+	"darkslategrey":        {47, 79, 79, 1},
+	"darkturquoise":        {0, 206, 209, 1},
+	"darkviolet":           {148, 0, 211, 1},
+	"deeppink":             {255, 20, 147, 1},
+	"deepskyblue":          {0, 191, 255, 1},
+	"dimgray":              {105, 105, 105, 1},
+	"dimgrey":              {105, 105, 105, 1},
+	"dodgerblue":           {30, 144, 255, 1},
+	"firebrick":            {178, 34, 34, 1},
+	"floralwhite":          {255, 250, 240, 1},
+	"forestgreen":          {34, 139, 34, 1},
+	"fuchsia":              {255, 0, 255, 1},
+	"gainsboro":            {220, 220, 220, 1},
+	"ghostwhite":           {248, 248, 255, 1},
+	"gold":                 {255, 215, 0, 1},
+	"goldenrod":            {218, 165, 32, 1},
+	"gray":                 {128, 128, 128, 1},
+	"green":                {0, 128, 0, 1},
+	"greenyellow":          {173, 255, 47, 1},
+	"grey":                 {128, 128, 128, 1},
+	"honeydew":             {240, 255, 240, 1},
+	"hotpink":              {255, 105, 180, 1},
+	"indianred":            {205, 92, 92, 1},
+	"indigo":               {75, 0, 130, 1},
+	"ivory":                {255, 255, 240, 1},
+	"khaki":                {240, 230, 140, 1},
+	"lavender":             {230, 230, 250, 1},
+	"lavenderblush":        {255, 240, 245, 1},
+	"lawngreen":            {124, 252, 0, 1},
+	"lemonchiffon":         {255, 250, 205, 1},
+	"lightblue":            {173, 216, 230, 1},
+	"lightcoral":           {240, 128, 128, 1},
+	"lightcyan":            {224, 255, 255, 1},
+	"lightgoldenrodyellow": {250, 250, 210, 1},
+	"lightgray":            {211, 211, 211, 1},
+	"lightgreen":           {144, 238, 144, 1},
+	"lightgrey":            {211, 211, 211, 1},
+	"lightpink":            {255, 182, 193, 1},
+	"lightsalmon":          {255, 160, 122, 1},
+	"lightseagreen":        {32, 178, 170, 1},
+	"lightskyblue":         {135, 206, 250, 1},
+	"lightslategray":       {119, 136, 153, 1},
+	"lightslategrey":       {119, 136, 153, 1},
+	"lightsteelblue":       {176, 196, 222, 1},
+	"lightyellow":          {255, 255, 224, 1},
+	"lime":                 {0, 255, 0, 1},
+	"limegreen":            {50, 205, 50, 1},
+	"linen":                {250, 240, 230, 1},
+	"magenta":              {255, 0, 255, 1},
+	"maroon":               {128, 0, 0, 1},
+	"mediumaquamarine":     {102, 205, 170, 1},
+	"mediumblue":           {0, 0, 205, 1},
+	"mediumorchid":         {186, 85, 211, 1},
+	"mediumpurple":         {147, 112, 219, 1},
+	"mediumseagreen":       {60, 179, 113, 1},
+	"mediumslateblue":      {123, 104, 238, 1},
+	"mediumspringgreen":    {0, 250, 154, 1},
+	"mediumturquoise":      {72, 209, 204, 1},
+	"mediumvioletred":      {199, 21, 133, 1},
+	"midnightblue":         {25, 25, 112, 1},
+	"mintcream":            {245, 255, 250, 1},
+	"mistyrose":            {255, 228, 225, 1},
+	"moccasin":             {255, 228, 181, 1},
+	"navajowhite":          {255, 222, 173, 1},
+	"navy":                 {0, 0, 128, 1},
+	"oldlace":              {253, 245, 230, 1},
+	"olive":                {128, 128, 0, 1},
+	"olivedrab":            {107, 142, 35, 1},
+	"orange":               {255, 165, 0, 1},
+	"orangered":            {255, 69, 0, 1},
+	"orchid":               {218, 112, 214, 1},
+	"palegoldenrod":        {238, 232, 170, 1},
+	"palegreen":            {152, 251, 152, 1},
+	"paleturquoise":        {175, 238, 238, 1},
+	"palevioletred":        {219, 112, 147, 1},
+	"papayawhip":           {255, 239, 213, 1},
+	"peachpuff":            {255, 218, 185, 1},
+	"peru":                 {205, 133, 63, 1},
+	"pink":                 {255, 192, 203, 1},
+	"plum":                 {221, 160, 221, 1},
+	"powderblue":           {176, 224, 230, 1},
+	"purple":               {128, 0, 128, 1},
+	"rebeccapurple":        {102, 51, 153, 1},
+	"red":                  {255, 0, 0, 1},
+	"rosybrown":            {188, 143, 143, 1},
+	"royalblue":            {65, 105, 225, 1},
+	"saddlebrown":          {139, 69, 19, 1},
+	"salmon":               {250, 128, 114, 1},
+	"sandybrown":           {244, 164, 96, 1},
+	"seagreen":             {46, 139, 87, 1},
+	"seashell":             {255, 245, 238, 1},
+	"sienna":               {160, 82, 45, 1},
+	"silver":               {192, 192, 192, 1},
+	"skyblue":              {135, 206, 235, 1},
+	"slateblue":            {106, 90, 205, 1},
+	"slategray":            {112, 128, 144, 1},
+	"slategrey":            {112, 128, 144, 1},
+	"snow":                 {255, 250, 250, 1},
+	"springgreen":          {0, 255, 127, 1},
+	"steelblue":            {70, 130, 180, 1},
+	"tan":                  {210, 180, 140, 1},
+	"teal":                 {0, 128, 128, 1},
+	"thistle":              {216, 191, 216, 1},
+	"tomato":               {255, 99, 71, 1},
+	"turquoise":            {64, 224, 208, 1},
+	"violet":               {238, 130, 238, 1},
+	"wheat":                {245, 222, 179, 1},
+	"white":                {255, 255, 255, 1},
+	"whitesmoke":           {245, 245, 245, 1},
+	"yellow":               {255, 255, 0, 1},
+	"yellowgreen":          {154, 205, 50, 1},
+}
@@ -0,0 +1,104 @@
+package quill
+
+import "testing"
+
+func TestParseColor(t *testing.T) {
+
+	valid := map[string]string{
+		"#a10000":                   "#a10000",
+		"#A10000":                   "#a10000",
+		"#fff":                      "#ffffff",
+		"#0f08":                     "rgba(0,255,0,0.533)",
+		"#00000080":                 "rgba(0,0,0,0.502)",
+		"red":                       "#ff0000",
+		" DarkSlateGray ":           "#2f4f4f",
+		"transparent":               "rgba(0,0,0,0)",
+		"rgb(230, 0, 0)":            "#e60000",
+		"rgb(100%, 0%, 50%)":        "#ff0080",
+		"rgba(0,0,0,0.5)":           "rgba(0,0,0,0.5)",
+		"rgba(0,0,0,1)":             "#000000",
+		"rgb(0 128 255 / 25%)":      "rgba(0,128,255,0.25)",
+		"rgb(300, -5, 12.6)":        "#ff000d",
+		"hsl(0, 100%, 50%)":         "#ff0000",
+		"hsl(120deg, 100%, 25%)":    "#008000",
+		"hsla(240, 100%, 50%, 0.1)": "rgba(0,0,255,0.1)",
+		"hsl(0.5turn 100% 50%)":     "#00ffff",
+		"hsl(-120, 100%, 50%)":      "#0000ff",
+	}
+	for in, want := range valid {
+		c, err := ParseColor(in)
+		if err != nil {
+			t.Errorf("could not parse %q: %s", in, err)
+			continue
+		}
+		if c.String() != want {
+			t.Errorf("parsed %q as %q; wanted %q", in, c.String(), want)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"#",
+		"#12",
+		"#12345",
+		"#1234567890",
+		"#ggg",
+		"red;position:fixed;top:0",
+		"redd",
+		"url(javascript:alert(1))",
+		"rgb(1,2)",
+		"rgb(1,2,3,4,5)",
+		"rgb(0x10,0,0)",
+		"rgb(Inf,0,0)",
+		"rgb(1, 2, 3",
+		"rgb(1 2 3 /)",
+		"hsl(0, 100, 50)",
+		"expression(alert(1))",
+		"currentcolor",
+	}
+	for _, in := range invalid {
+		if c, err := ParseColor(in); err == nil {
+			t.Errorf("parsed invalid color %q as %s", in, c)
+		}
+	}
+
+}
+
+func TestColorPolicy(t *testing.T) {
+
+	p, err := NewColorPolicy("#e60000", "rgb(0, 0, 0)", "white")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		css string
+		ok  bool
+	}{
+		"#E60000": {"#e60000", true},
+		"black":   {"#000000", true},
+		"#fff":    {"#ffffff", true},
+		"blue":    {"", false},
+		"garbage": {"", false},
+	}
+	for raw, want := range cases {
+		css, ok := p.Color(raw)
+		if css != want.css || ok != want.ok {
+			t.Errorf("checking %q; got (%q, %v)", raw, css, ok)
+		}
+	}
+
+	ops := `[{"attributes":{"color":"#e60000","background":"blue"},"insert":"text"},{"insert":"\n"}]`
+	got, err := RenderExtended([]byte(ops), p.Formatter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<p><span style="color:#e60000;">text</span></p>`; string(got) != want {
+		t.Errorf("bad rendering; got: %s", got)
+	}
+
+	if _, err := NewColorPolicy("red", "not-a-color"); err == nil {
+		t.Errorf("no error for an invalid palette")
+	}
+
+}
@@ -0,0 +1,603 @@
+package quill
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+)
+
+// A Delta is a list of operations that either describes a document (when it has only inserts) or a change to a
+// document (when it also retains and deletes). Its methods follow those of the quilljs/delta library, and the results
+// are the same.
+//
+// Lengths and positions count UTF-16 code units, as they do in JavaScript, so that they agree with those in the changes
+// sent by an editor. An embed has a length of 1.
+type Delta struct {
+	Ops []DeltaOp
+}
+
+// A DeltaOp is a single operation of a Delta. Exactly one of Insert, Retain or Delete is set.
+//
+// An Insert is either a string or, for an embed, a map with a single key that names the type of embed. Attributes go
+// along with inserts and retains; on a retain, an attribute with a nil value removes that attribute.
+type DeltaOp struct {
+	Insert     interface{}            `json:"insert,omitempty"`
+	Retain     int                    `json:"retain,omitempty"`
+	Delete     int                    `json:"delete,omitempty"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// Length returns the length of the operation.
+func (op *DeltaOp) Length() int {
+	switch {
+	case op.Delete > 0:
+		return op.Delete
+	case op.Retain > 0:
+		return op.Retain
+	}
+	if s, ok := op.Insert.(string); ok {
+		return utf16Len(s)
+	}
+	return 1
+}
+
+// UnmarshalJSON reads an operation, checking that it is one that a Delta may have.
+func (op *DeltaOp) UnmarshalJSON(data []byte) error {
+
+	var raw struct {
+		Insert     interface{}            `json:"insert"`
+		Retain     *float64               `json:"retain"`
+		Delete     *float64               `json:"delete"`
+		Attributes map[string]interface{} `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	set := 0
+	*op = DeltaOp{Attributes: raw.Attributes}
+	if raw.Insert != nil {
+		switch ins := raw.Insert.(type) {
+		case string:
+		case map[string]interface{}:
+			if len(ins) != 1 {
+				return fmt.Errorf("quill: an embed insert must have a single key: %s", data)
+			}
+		default:
+			return fmt.Errorf("quill: an insert must be a string or an object: %s", data)
+		}
+		op.Insert = raw.Insert
+		set++
+	}
+	if raw.Retain != nil {
+		if op.Retain = int(*raw.Retain); float64(op.Retain) != *raw.Retain || op.Retain <= 0 {
+			return fmt.Errorf("quill: a retain must be a positive integer: %s", data)
+		}
+		set++
+	}
+	if raw.Delete != nil {
+		if op.Delete = int(*raw.Delete); float64(op.Delete) != *raw.Delete || op.Delete <= 0 {
+			return fmt.Errorf("quill: a delete must be a positive integer: %s", data)
+		}
+		set++
+	}
+	if set != 1 {
+		return fmt.Errorf("quill: an op must have exactly one of insert, retain or delete: %s", data)
+	}
+
+	return nil
+
+}
+
+// MarshalJSON writes the Delta as a JSON array of operations, which is how Render takes a document.
+func (d *Delta) MarshalJSON() ([]byte, error) {
+	if d.Ops == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(d.Ops)
+}
+
+// UnmarshalJSON reads a Delta either as an array of operations or as an object with an "ops" array, as the quilljs/delta
+// library writes it.
+func (d *Delta) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimLeft(data, " \t\r\n"); len(trimmed) == 0 || trimmed[0] != '{' {
+		var ops []DeltaOp
+		if err := json.Unmarshal(data, &ops); err != nil {
+			return err
+		}
+		d.Ops = ops
+		return nil
+	}
+	var obj struct {
+		Ops []DeltaOp `json:"ops"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	d.Ops = obj.Ops
+	return nil
+}
+
+// Insert adds an insert of text to the Delta.
+func (d *Delta) Insert(text string, attrs map[string]interface{}) *Delta {
+	if text == "" {
+		return d
+	}
+	return d.Push(DeltaOp{Insert: text, Attributes: attrs})
+}
+
+// InsertEmbed adds an insert of an embed of the named type (such as "image") to the Delta.
+func (d *Delta) InsertEmbed(embed string, value interface{}, attrs map[string]interface{}) *Delta {
+	return d.Push(DeltaOp{Insert: map[string]interface{}{embed: value}, Attributes: attrs})
+}
+
+// Retain adds a retain to the Delta, which keeps the next n units of the document and sets the attributes on them.
+func (d *Delta) Retain(n int, attrs map[string]interface{}) *Delta {
+	if n <= 0 {
+		return d
+	}
+	return d.Push(DeltaOp{Retain: n, Attributes: attrs})
+}
+
+// Delete adds a delete of the next n units of the document to the Delta.
+func (d *Delta) Delete(n int) *Delta {
+	if n <= 0 {
+		return d
+	}
+	return d.Push(DeltaOp{Delete: n})
+}
+
+// Push adds an operation to the end of the Delta, merging it with the last operation if they are of the same kind and
+// have the same attributes. An insert is always put before a delete at the same position.
+func (d *Delta) Push(op DeltaOp) *Delta {
+
+	if len(op.Attributes) == 0 {
+		op.Attributes = nil
+	} else {
+		op.Attributes = copyAttributes(op.Attributes)
+	}
+
+	index := len(d.Ops)
+	if index > 0 {
+		last := &d.Ops[index-1]
+		if op.Delete > 0 && last.Delete > 0 {
+			last.Delete += op.Delete
+			return d
+		}
+		if last.Delete > 0 && op.Insert != nil {
+			index--
+			if index == 0 {
+				d.Ops = append([]DeltaOp{op}, d.Ops...)
+				return d
+			}
+			last = &d.Ops[index-1]
+		}
+		if reflect.DeepEqual(op.Attributes, last.Attributes) {
+			lastText, lastOK := last.Insert.(string)
+			if text, ok := op.Insert.(string); ok && lastOK {
+				last.Insert = lastText + text
+				return d
+			}
+			if op.Retain > 0 && last.Retain > 0 {
+				last.Retain += op.Retain
+				return d
+			}
+		}
+	}
+
+	d.Ops = append(d.Ops, DeltaOp{})
+	copy(d.Ops[index+1:], d.Ops[index:])
+	d.Ops[index] = op
+	return d
+
+}
+
+// Chop removes a trailing retain that has no attributes, since it does not change anything.
+func (d *Delta) Chop() *Delta {
+	if n := len(d.Ops); n > 0 && d.Ops[n-1].Retain > 0 && d.Ops[n-1].Attributes == nil {
+		d.Ops = d.Ops[:n-1]
+	}
+	return d
+}
+
+// Length returns the total length of the operations of the Delta.
+func (d *Delta) Length() int {
+	n := 0
+	for i := range d.Ops {
+		n += d.Ops[i].Length()
+	}
+	return n
+}
+
+// Compose returns a Delta that has the effect of applying d and then other. Composing a document with a change gives
+// the changed document.
+func (d *Delta) Compose(other *Delta) *Delta {
+
+	it, otherIt := deltaIterator{ops: d.Ops}, deltaIterator{ops: other.Ops}
+	out := new(Delta)
+
+	// Inserts at the start of d that are only retained by other can be taken as they are.
+	if first := otherIt.peek(); first != nil && first.Retain > 0 && first.Attributes == nil {
+		firstLeft := first.Retain
+		for it.peekType() == insertOp && it.peekLength() <= firstLeft {
+			firstLeft -= it.peekLength()
+			out.Ops = append(out.Ops, it.next(infinity))
+		}
+		if first.Retain-firstLeft > 0 {
+			otherIt.next(first.Retain - firstLeft)
+		}
+	}
+
+	for it.hasNext() || otherIt.hasNext() {
+		switch {
+		case otherIt.peekType() == insertOp:
+			out.Push(otherIt.next(infinity))
+		case it.peekType() == deleteOp:
+			out.Push(it.next(infinity))
+		default:
+			length := minInt(it.peekLength(), otherIt.peekLength())
+			thisOp, otherOp := it.next(length), otherIt.next(length)
+			if otherOp.Retain > 0 {
+				newOp := DeltaOp{}
+				if thisOp.Retain > 0 {
+					newOp.Retain = length
+				} else {
+					newOp.Insert = thisOp.Insert
+				}
+				newOp.Attributes = composeAttributes(thisOp.Attributes, otherOp.Attributes, thisOp.Retain > 0)
+				out.Push(newOp)
+				// If the rest of other only retains, the rest of d can be taken as it is.
+				if !otherIt.hasNext() && reflect.DeepEqual(out.Ops[len(out.Ops)-1], newOp) {
+					rest := it.rest()
+					if len(rest) > 0 {
+						out.Push(rest[0])
+						out.Ops = append(out.Ops, rest[1:]...)
+					}
+					return out.Chop()
+				}
+			} else if otherOp.Delete > 0 && thisOp.Retain > 0 {
+				out.Push(otherOp)
+			}
+			// Otherwise other deletes an insert of d, and the two cancel out.
+		}
+	}
+
+	return out.Chop()
+
+}
+
+// Transform returns other changed so that it can be applied after d, when both d and other were made to the same
+// document. If priority is set, d is taken to have happened first: where both insert at the same position, the insert
+// of d goes first, and where both set an attribute, the value of d is kept.
+func (d *Delta) Transform(other *Delta, priority bool) *Delta {
+
+	it, otherIt := deltaIterator{ops: d.Ops}, deltaIterator{ops: other.Ops}
+	out := new(Delta)
+
+	for it.hasNext() || otherIt.hasNext() {
+		switch {
+		case it.peekType() == insertOp && (priority || otherIt.peekType() != insertOp):
+			op := it.next(infinity)
+			out.Retain(op.Length(), nil)
+		case otherIt.peekType() == insertOp:
+			out.Push(otherIt.next(infinity))
+		default:
+			length := minInt(it.peekLength(), otherIt.peekLength())
+			thisOp, otherOp := it.next(length), otherIt.next(length)
+			switch {
+			case thisOp.Delete > 0:
+				// The delete of d either makes the delete of other redundant or removes what other retains.
+			case otherOp.Delete > 0:
+				out.Push(otherOp)
+			default:
+				out.Retain(length, transformAttributes(thisOp.Attributes, otherOp.Attributes, priority))
+			}
+		}
+	}
+
+	return out.Chop()
+
+}
+
+// TransformPosition returns where the position index moves to when d is applied. If priority is set, an insert at the
+// position itself is taken to be after it, leaving the position where it was.
+func (d *Delta) TransformPosition(index int, priority bool) int {
+	it := deltaIterator{ops: d.Ops}
+	offset := 0
+	for it.hasNext() && offset <= index {
+		length, kind := it.peekLength(), it.peekType()
+		it.next(infinity)
+		if kind == deleteOp {
+			index -= minInt(length, index-offset)
+			continue
+		}
+		if kind == insertOp && (offset < index || !priority) {
+			index += length
+		}
+		offset += length
+	}
+	return index
+}
+
+// Invert returns the change that undoes d, which is a change made to the document base.
+func (d *Delta) Invert(base *Delta) *Delta {
+
+	out := new(Delta)
+	baseIndex := 0
+
+	for i := range d.Ops {
+		op := &d.Ops[i]
+		switch {
+		case op.Insert != nil:
+			out.Delete(op.Length())
+		case op.Retain > 0 && op.Attributes == nil:
+			out.Retain(op.Retain, nil)
+			baseIndex += op.Retain
+		default:
+			length := op.Length()
+			for _, baseOp := range base.Slice(baseIndex, baseIndex+length).Ops {
+				if op.Delete > 0 {
+					out.Push(baseOp)
+				} else {
+					out.Retain(baseOp.Length(), invertAttributes(op.Attributes, baseOp.Attributes))
+				}
+			}
+			baseIndex += length
+		}
+	}
+
+	return out.Chop()
+
+}
+
+// Slice returns the part of d from the position start up to end.
+func (d *Delta) Slice(start, end int) *Delta {
+	out := new(Delta)
+	it := deltaIterator{ops: d.Ops}
+	for index := 0; index < end && it.hasNext(); {
+		var op DeltaOp
+		if index < start {
+			op = it.next(start - index)
+		} else {
+			op = it.next(end - index)
+			out.Ops = append(out.Ops, op)
+		}
+		index += op.Length()
+	}
+	return out
+}
+
+// ErrNotDocument is returned when a Delta that retains or deletes is used as a document.
+var ErrNotDocument = errors.New("quill: the delta has operations other than inserts")
+
+// Document splits a Delta that has only inserts into the blocks of a Document, which can then be rendered. The built-in
+// formats say which embeds are blocks of their own.
+func (d *Delta) Document() (*Document, error) {
+
+	doc := new(Document)
+	bs := blockSplitter{r: defaultRenderer, emit: func(b *Block) error {
+		doc.Blocks = append(doc.Blocks, b)
+		return nil
+	}}
+
+	for i := range d.Ops {
+		if d.Ops[i].Insert == nil {
+			return nil, ErrNotDocument
+		}
+		ro := rawOp{Insert: d.Ops[i].Insert, Attrs: d.Ops[i].Attributes}
+		o, err := ro.op()
+		if err != nil {
+			return nil, err
+		}
+		bs.add(o) // The emit function does not return errors.
+	}
+	bs.end()
+
+	return doc, nil
+
+}
+
+// composeAttributes returns the attributes that result from setting b on top of a. Unless keepNull is set, the nil
+// values in b, which remove an attribute, are left out.
+func composeAttributes(a, b map[string]interface{}, keepNull bool) map[string]interface{} {
+	attrs := make(map[string]interface{}, len(a)+len(b))
+	for k, v := range b {
+		if v != nil || keepNull {
+			attrs[k] = v
+		}
+	}
+	for k, v := range a {
+		if _, ok := b[k]; !ok {
+			attrs[k] = v
+		}
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+// transformAttributes returns the attributes that a retain of other sets when it is made after d, which sets a. If
+// priority is set, the attributes that a also sets are left out.
+func transformAttributes(a, b map[string]interface{}, priority bool) map[string]interface{} {
+	if a == nil || !priority {
+		return b
+	}
+	attrs := make(map[string]interface{}, len(b))
+	for k, v := range b {
+		if _, ok := a[k]; !ok {
+			attrs[k] = v
+		}
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+// invertAttributes returns the attributes that a retain sets to undo setting attrs on text that had the attributes
+// base.
+func invertAttributes(attrs, base map[string]interface{}) map[string]interface{} {
+	inv := make(map[string]interface{})
+	for k, v := range base {
+		if av, ok := attrs[k]; ok && !reflect.DeepEqual(av, v) {
+			inv[k] = v
+		}
+	}
+	for k := range attrs {
+		if _, ok := base[k]; !ok {
+			inv[k] = nil
+		}
+	}
+	return inv
+}
+
+// copyAttributes returns a copy of the attributes map.
+func copyAttributes(attrs map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(attrs))
+	for k, v := range attrs {
+		c[k] = v
+	}
+	return c
+}
+
+// The kinds of DeltaOp, as given by deltaIterator.peekType.
+const (
+	insertOp = iota
+	retainOp
+	deleteOp
+)
+
+// infinity is the length of the endless retain that follows the last op of a deltaIterator.
+const infinity = int(^uint(0) >> 1)
+
+// A deltaIterator goes through the ops of a Delta, taking them in pieces of any length.
+type deltaIterator struct {
+	ops    []DeltaOp
+	index  int // the current op
+	offset int // how much of the current op was already taken
+}
+
+func (it *deltaIterator) hasNext() bool {
+	return it.peekLength() < infinity
+}
+
+// peek returns the current op, or nil at the end.
+func (it *deltaIterator) peek() *DeltaOp {
+	if it.index < len(it.ops) {
+		return &it.ops[it.index]
+	}
+	return nil
+}
+
+// peekLength returns what is left of the current op, which at the end is an endless retain.
+func (it *deltaIterator) peekLength() int {
+	if op := it.peek(); op != nil {
+		return op.Length() - it.offset
+	}
+	return infinity
+}
+
+// peekType returns the kind of the current op, which at the end is a retain.
+func (it *deltaIterator) peekType() int {
+	op := it.peek()
+	switch {
+	case op == nil, op.Retain > 0:
+		return retainOp
+	case op.Delete > 0:
+		return deleteOp
+	}
+	return insertOp
+}
+
+// next takes up to length units of the current op, moving on to the next op when the current one is used up.
+func (it *deltaIterator) next(length int) DeltaOp {
+
+	op := it.peek()
+	if op == nil {
+		return DeltaOp{Retain: infinity}
+	}
+
+	offset := it.offset
+	if opLength := op.Length(); length >= opLength-offset {
+		length = opLength - offset
+		it.index++
+		it.offset = 0
+	} else {
+		it.offset += length
+	}
+
+	switch {
+	case op.Delete > 0:
+		return DeltaOp{Delete: length}
+	case op.Retain > 0:
+		return DeltaOp{Retain: length, Attributes: op.Attributes}
+	}
+	if s, ok := op.Insert.(string); ok {
+		return DeltaOp{Insert: utf16Slice(s, offset, offset+length), Attributes: op.Attributes}
+	}
+	return DeltaOp{Insert: op.Insert, Attributes: op.Attributes}
+
+}
+
+// rest returns the ops that are left, starting with what is left of the current op, without moving the iterator.
+func (it *deltaIterator) rest() []DeltaOp {
+	if !it.hasNext() {
+		return nil
+	}
+	if it.offset == 0 {
+		return append([]DeltaOp(nil), it.ops[it.index:]...)
+	}
+	index, offset := it.index, it.offset
+	next := it.next(infinity)
+	rest := append([]DeltaOp{next}, it.ops[it.index:]...)
+	it.index, it.offset = index, offset
+	return rest
+}
+
+// utf16Len returns the length of s in UTF-16 code units.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16RuneLen(r)
+	}
+	return n
+}
+
+// utf16Slice returns the part of s from the UTF-16 position start up to end. A character that is split by a position is
+// put with the part in which it starts.
+func utf16Slice(s string, start, end int) string {
+	from, to := len(s), len(s)
+	pos := 0
+	for i, r := range s {
+		if pos >= start && from == len(s) {
+			from = i
+		}
+		if pos >= end {
+			to = i
+			break
+		}
+		pos += utf16RuneLen(r)
+	}
+	if from > to {
+		return ""
+	}
+	return s[from:to]
+}
+
+func utf16RuneLen(r rune) int {
+	if r >= 0x10000 && r <= utf8.MaxRune {
+		return 2
+	}
+	return 1
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,538 @@
+package quill
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// attrs is a shorthand for making attribute maps in tests.
+type attrs = map[string]interface{}
+
+func TestDelta_Compose(t *testing.T) {
+
+	bold := attrs{"bold": true}
+	image := func() *Delta { return new(Delta).InsertEmbed("image", "http://quilljs.com/image.png", nil) }
+
+	// The cases are those of the quilljs/delta library.
+	cases := map[string]struct {
+		a, b, want *Delta
+	}{
+		"insert + insert": {
+			a:    new(Delta).Insert("A", nil),
+			b:    new(Delta).Insert("B", nil),
+			want: new(Delta).Insert("B", nil).Insert("A", nil),
+		},
+		"insert + retain": {
+			a:    new(Delta).Insert("A", nil),
+			b:    new(Delta).Retain(1, attrs{"bold": true, "color": "red", "font": nil}),
+			want: new(Delta).Insert("A", attrs{"bold": true, "color": "red"}),
+		},
+		"insert + delete": {
+			a:    new(Delta).Insert("A", nil),
+			b:    new(Delta).Delete(1),
+			want: new(Delta),
+		},
+		"delete + insert": {
+			a:    new(Delta).Delete(1),
+			b:    new(Delta).Insert("B", nil),
+			want: new(Delta).Insert("B", nil).Delete(1),
+		},
+		"delete + retain": {
+			a:    new(Delta).Delete(1),
+			b:    new(Delta).Retain(1, attrs{"bold": true, "color": "red"}),
+			want: new(Delta).Delete(1).Retain(1, attrs{"bold": true, "color": "red"}),
+		},
+		"delete + delete": {
+			a:    new(Delta).Delete(1),
+			b:    new(Delta).Delete(1),
+			want: new(Delta).Delete(2),
+		},
+		"retain + insert": {
+			a:    new(Delta).Retain(1, attrs{"color": "blue"}),
+			b:    new(Delta).Insert("B", nil),
+			want: new(Delta).Insert("B", nil).Retain(1, attrs{"color": "blue"}),
+		},
+		"retain + retain": {
+			a:    new(Delta).Retain(1, attrs{"color": "blue"}),
+			b:    new(Delta).Retain(1, attrs{"bold": true, "color": "red", "font": nil}),
+			want: new(Delta).Retain(1, attrs{"bold": true, "color": "red", "font": nil}),
+		},
+		"retain + delete": {
+			a:    new(Delta).Retain(1, attrs{"color": "blue"}),
+			b:    new(Delta).Delete(1),
+			want: new(Delta).Delete(1),
+		},
+		"insert in middle of text": {
+			a:    new(Delta).Insert("Hello", nil),
+			b:    new(Delta).Retain(3, nil).Insert("X", nil),
+			want: new(Delta).Insert("HelXlo", nil),
+		},
+		"insert then delete": {
+			a:    new(Delta).Insert("Hello", nil),
+			b:    new(Delta).Retain(3, nil).Insert("X", nil).Delete(1),
+			want: new(Delta).Insert("HelXo", nil),
+		},
+		"delete then insert": {
+			a:    new(Delta).Insert("Hello", nil),
+			b:    new(Delta).Retain(3, nil).Delete(1).Insert("X", nil),
+			want: new(Delta).Insert("HelXo", nil),
+		},
+		"insert embed": {
+			a:    image(),
+			b:    new(Delta).Retain(1, attrs{"alt": "logo"}),
+			want: new(Delta).InsertEmbed("image", "http://quilljs.com/image.png", attrs{"alt": "logo"}),
+		},
+		"delete entire text": {
+			a:    new(Delta).Retain(4, nil).Insert("Hello", nil),
+			b:    new(Delta).Delete(9),
+			want: new(Delta).Delete(4),
+		},
+		"retain more than length of text": {
+			a:    new(Delta).Insert("Hello", nil),
+			b:    new(Delta).Retain(10, nil),
+			want: new(Delta).Insert("Hello", nil),
+		},
+		"retain empty embed": {
+			a:    image(),
+			b:    new(Delta).Retain(1, nil),
+			want: image(),
+		},
+		"remove all attributes": {
+			a:    new(Delta).Insert("A", bold),
+			b:    new(Delta).Retain(1, attrs{"bold": nil}),
+			want: new(Delta).Insert("A", nil),
+		},
+		"remove all embed attributes": {
+			a:    new(Delta).InsertEmbed("image", "a.png", bold),
+			b:    new(Delta).Retain(1, attrs{"bold": nil}),
+			want: new(Delta).InsertEmbed("image", "a.png", nil),
+		},
+		"retain start optimization": {
+			a:    new(Delta).Insert("A", bold).Insert("B", nil).Insert("C", bold).Delete(1),
+			b:    new(Delta).Retain(3, nil).Insert("D", nil),
+			want: new(Delta).Insert("A", bold).Insert("B", nil).Insert("C", bold).Insert("D", nil).Delete(1),
+		},
+		"retain start optimization split": {
+			a: new(Delta).Insert("A", bold).Insert("B", nil).Insert("C", bold).Retain(5, nil).Delete(1),
+			b: new(Delta).Retain(4, nil).Insert("D", nil),
+			want: new(Delta).Insert("A", bold).Insert("B", nil).Insert("C", bold).Retain(1, nil).Insert("D", nil).
+				Retain(4, nil).Delete(1),
+		},
+		"retain end optimization": {
+			a:    new(Delta).Insert("A", bold).Insert("B", nil).Insert("C", bold),
+			b:    new(Delta).Delete(1),
+			want: new(Delta).Insert("B", nil).Insert("C", bold),
+		},
+		"retain end optimization join": {
+			a: new(Delta).Insert("A", bold).Insert("B", nil).Insert("C", bold).Insert("D", nil).Insert("E", bold).
+				Insert("F", nil),
+			b:    new(Delta).Retain(1, nil).Delete(1),
+			want: new(Delta).Insert("AC", bold).Insert("D", nil).Insert("E", bold).Insert("F", nil),
+		},
+		"surrogate pairs": {
+			a:    new(Delta).Insert("a\U0001F600b", nil),
+			b:    new(Delta).Retain(3, nil).Insert("X", nil).Delete(1),
+			want: new(Delta).Insert("a\U0001F600X", nil),
+		},
+	}
+
+	for name, c := range cases {
+		aJSON, _ := json.Marshal(c.a)
+		bJSON, _ := json.Marshal(c.b)
+		got := c.a.Compose(c.b)
+		if !sameDelta(got, c.want) {
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(c.want)
+			t.Errorf("%s: got %s; want %s", name, gotJSON, wantJSON)
+		}
+		// The deltas being composed are left as they were.
+		aAfter, _ := json.Marshal(c.a)
+		bAfter, _ := json.Marshal(c.b)
+		if string(aJSON) != string(aAfter) || string(bJSON) != string(bAfter) {
+			t.Errorf("%s: Compose changed its input", name)
+		}
+	}
+
+}
+
+func TestDelta_JSON(t *testing.T) {
+
+	var d Delta
+	change := `{"ops":[{"retain":5},{"retain":3,"attributes":{"bold":null,"color":"#a10000"}},{"insert":"x"},{"delete":2}]}`
+	if err := json.Unmarshal([]byte(change), &d); err != nil {
+		t.Fatal(err)
+	}
+	want := new(Delta).Retain(5, nil).Retain(3, attrs{"bold": nil, "color": "#a10000"}).Insert("x", nil).Delete(2)
+	if !reflect.DeepEqual(d.Ops, want.Ops) {
+		t.Errorf("bad ops read: %+v", d.Ops)
+	}
+
+	got, err := json.Marshal(&d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := `[{"retain":5},{"retain":3,"attributes":{"bold":null,"color":"#a10000"}},{"insert":"x"},{"delete":2}]`; string(got) != s {
+		t.Errorf("bad JSON; got: %s", got)
+	}
+
+	for _, bad := range []string{
+		`[{"attributes":{"bold":true}}]`,
+		`[{"insert":"a","delete":1}]`,
+		`[{"retain":1.5}]`,
+		`[{"delete":0}]`,
+		`[{"insert":5}]`,
+		`[{"insert":{}}]`,
+		`[{"retain":-1}]`,
+		` {"ops":[{"retain":-1}]}`,
+	} {
+		// The error is that of the op, whichever form the Delta has.
+		if err := json.Unmarshal([]byte(bad), &d); err == nil || !strings.HasPrefix(err.Error(), "quill: ") {
+			t.Errorf("bad error for %s: %v", bad, err)
+		}
+	}
+
+}
+
+func TestDelta_Document(t *testing.T) {
+
+	var doc, change Delta
+	if err := json.Unmarshal([]byte(`[{"insert":"Title\nSome text.\n"}]`), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`[{"retain":5},{"retain":1,"attributes":{"header":1}},{"retain":5},{"insert":"bold ","attributes":{"bold":true}}]`), &change); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := doc.Compose(&change).Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	html, err := defaultRenderer.RenderDocument(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<h1>Title</h1><p>Some <strong>bold </strong>text.</p>"; string(html) != want {
+		t.Errorf("bad render of the composed document; got: %s", html)
+	}
+
+	if _, err := change.Document(); err != ErrNotDocument {
+		t.Errorf("got error %v for a change; want ErrNotDocument", err)
+	}
+
+	// A Delta built in Go may have attributes of any numeric type.
+	built := new(Delta).Insert("Title", nil).Insert("\n", attrs{"header": 1}).
+		InsertEmbed("image", "a.png", attrs{"width": int64(100)}).Insert("item", nil).
+		Insert("\n", attrs{"list": "bullet", "indent": uint8(2)})
+	if d, err = built.Document(); err != nil {
+		t.Fatal(err)
+	}
+	if html, err = defaultRenderer.RenderDocument(d); err != nil {
+		t.Fatal(err)
+	}
+	if want := `<h1>Title</h1><ul><li class="indent-2"><img src="a.png" width="100" loading="lazy">item</li></ul>`; string(html) != want {
+		t.Errorf("bad render of a built document; got: %s", html)
+	}
+
+}
+
+func TestDelta_Transform(t *testing.T) {
+
+	colorBlue := attrs{"color": "blue"}
+	boldRed := attrs{"bold": true, "color": "red"}
+
+	// The cases are those of the quilljs/delta library.
+	cases := map[string]struct {
+		a, b     *Delta
+		priority bool
+		want     *Delta
+	}{
+		"insert + insert": {
+			a:        new(Delta).Insert("A", nil),
+			b:        new(Delta).Insert("B", nil),
+			priority: true,
+			want:     new(Delta).Retain(1, nil).Insert("B", nil),
+		},
+		"insert + insert without priority": {
+			a:    new(Delta).Insert("A", nil),
+			b:    new(Delta).Insert("B", nil),
+			want: new(Delta).Insert("B", nil),
+		},
+		"insert + retain": {
+			a:        new(Delta).Insert("A", nil),
+			b:        new(Delta).Retain(1, boldRed),
+			priority: true,
+			want:     new(Delta).Retain(1, nil).Retain(1, boldRed),
+		},
+		"insert + delete": {
+			a:        new(Delta).Insert("A", nil),
+			b:        new(Delta).Delete(1),
+			priority: true,
+			want:     new(Delta).Retain(1, nil).Delete(1),
+		},
+		"delete + insert": {
+			a:        new(Delta).Delete(1),
+			b:        new(Delta).Insert("B", nil),
+			priority: true,
+			want:     new(Delta).Insert("B", nil),
+		},
+		"delete + retain": {
+			a:        new(Delta).Delete(1),
+			b:        new(Delta).Retain(1, boldRed),
+			priority: true,
+			want:     new(Delta),
+		},
+		"delete + delete": {
+			a:        new(Delta).Delete(1),
+			b:        new(Delta).Delete(1),
+			priority: true,
+			want:     new(Delta),
+		},
+		"retain + insert": {
+			a:        new(Delta).Retain(1, colorBlue),
+			b:        new(Delta).Insert("B", nil),
+			priority: true,
+			want:     new(Delta).Insert("B", nil),
+		},
+		"retain + retain": {
+			a:        new(Delta).Retain(1, colorBlue),
+			b:        new(Delta).Retain(1, boldRed),
+			priority: true,
+			want:     new(Delta).Retain(1, attrs{"bold": true}),
+		},
+		"retain + retain reversed": {
+			a:        new(Delta).Retain(1, boldRed),
+			b:        new(Delta).Retain(1, colorBlue),
+			priority: true,
+			want:     new(Delta),
+		},
+		"retain + retain without priority": {
+			a:    new(Delta).Retain(1, colorBlue),
+			b:    new(Delta).Retain(1, boldRed),
+			want: new(Delta).Retain(1, boldRed),
+		},
+		"retain + retain without priority reversed": {
+			a:    new(Delta).Retain(1, boldRed),
+			b:    new(Delta).Retain(1, colorBlue),
+			want: new(Delta).Retain(1, colorBlue),
+		},
+		"retain + delete": {
+			a:        new(Delta).Retain(1, colorBlue),
+			b:        new(Delta).Delete(1),
+			priority: true,
+			want:     new(Delta).Delete(1),
+		},
+		"alternating edits": {
+			a:    new(Delta).Retain(2, nil).Insert("si", nil).Delete(5),
+			b:    new(Delta).Retain(1, nil).Insert("e", nil).Delete(5).Retain(1, nil).Insert("ow", nil),
+			want: new(Delta).Retain(1, nil).Insert("e", nil).Delete(1).Retain(2, nil).Insert("ow", nil),
+		},
+		"alternating edits reversed": {
+			a:    new(Delta).Retain(1, nil).Insert("e", nil).Delete(5).Retain(1, nil).Insert("ow", nil),
+			b:    new(Delta).Retain(2, nil).Insert("si", nil).Delete(5),
+			want: new(Delta).Retain(2, nil).Insert("si", nil).Delete(1),
+		},
+		"conflicting appends": {
+			a:        new(Delta).Retain(3, nil).Insert("aa", nil),
+			b:        new(Delta).Retain(3, nil).Insert("bb", nil),
+			priority: true,
+			want:     new(Delta).Retain(5, nil).Insert("bb", nil),
+		},
+		"conflicting appends reversed": {
+			a:    new(Delta).Retain(3, nil).Insert("bb", nil),
+			b:    new(Delta).Retain(3, nil).Insert("aa", nil),
+			want: new(Delta).Retain(3, nil).Insert("aa", nil),
+		},
+		"prepend + append": {
+			a:    new(Delta).Insert("aa", nil),
+			b:    new(Delta).Retain(3, nil).Insert("bb", nil),
+			want: new(Delta).Retain(5, nil).Insert("bb", nil),
+		},
+		"prepend + append reversed": {
+			a:    new(Delta).Retain(3, nil).Insert("bb", nil),
+			b:    new(Delta).Insert("aa", nil),
+			want: new(Delta).Insert("aa", nil),
+		},
+		"trailing deletes with differing lengths": {
+			a:    new(Delta).Retain(2, nil).Delete(1),
+			b:    new(Delta).Delete(3),
+			want: new(Delta).Delete(2),
+		},
+		"trailing deletes with differing lengths reversed": {
+			a:    new(Delta).Delete(3),
+			b:    new(Delta).Retain(2, nil).Delete(1),
+			want: new(Delta),
+		},
+	}
+
+	for name, c := range cases {
+		aJSON, _ := json.Marshal(c.a)
+		bJSON, _ := json.Marshal(c.b)
+		got := c.a.Transform(c.b, c.priority)
+		if !sameDelta(got, c.want) {
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(c.want)
+			t.Errorf("%s: got %s; want %s", name, gotJSON, wantJSON)
+		}
+		aAfter, _ := json.Marshal(c.a)
+		bAfter, _ := json.Marshal(c.b)
+		if string(aJSON) != string(aAfter) || string(bJSON) != string(bAfter) {
+			t.Errorf("%s: Transform changed its input", name)
+		}
+	}
+
+}
+
+func TestDelta_Transform_converges(t *testing.T) {
+	// Applying a and then b transformed, or b and then a transformed, gives the same document.
+	doc := new(Delta).Insert("Hello world\n", nil)
+	a := new(Delta).Retain(6, nil).Insert("brave new ", nil).Retain(5, attrs{"bold": true})
+	b := new(Delta).Retain(4, nil).Delete(3).Retain(4, attrs{"bold": nil, "italic": true}).Insert("!", nil)
+	ab := doc.Compose(a).Compose(a.Transform(b, true))
+	ba := doc.Compose(b).Compose(b.Transform(a, false))
+	if !sameDelta(ab, ba) {
+		abJSON, _ := json.Marshal(ab)
+		baJSON, _ := json.Marshal(ba)
+		t.Errorf("the documents differ:\n%s\n%s", abJSON, baJSON)
+	}
+}
+
+func TestDelta_TransformPosition(t *testing.T) {
+
+	// The cases are those of the quilljs/delta library.
+	cases := map[string]struct {
+		d        *Delta
+		index    int
+		priority bool
+		want     int
+	}{
+		"insert before position": {
+			d:     new(Delta).Insert("A", nil),
+			index: 2,
+			want:  3,
+		},
+		"insert after position": {
+			d:     new(Delta).Retain(2, nil).Insert("A", nil),
+			index: 1,
+			want:  1,
+		},
+		"insert at position": {
+			d:        new(Delta).Retain(2, nil).Insert("A", nil),
+			index:    2,
+			priority: true,
+			want:     2,
+		},
+		"insert at position without priority": {
+			d:     new(Delta).Retain(2, nil).Insert("A", nil),
+			index: 2,
+			want:  3,
+		},
+		"delete before position": {
+			d:     new(Delta).Delete(2),
+			index: 4,
+			want:  2,
+		},
+		"delete after position": {
+			d:     new(Delta).Retain(4, nil).Delete(2),
+			index: 2,
+			want:  2,
+		},
+		"delete across position": {
+			d:     new(Delta).Retain(1, nil).Delete(4),
+			index: 2,
+			want:  1,
+		},
+		"insert and delete before position": {
+			d:     new(Delta).Retain(2, nil).Insert("A", nil).Delete(2),
+			index: 4,
+			want:  3,
+		},
+		"insert before and delete across position": {
+			d:     new(Delta).Retain(2, nil).Insert("A", nil).Delete(4),
+			index: 4,
+			want:  3,
+		},
+		"delete before and delete across position": {
+			d:     new(Delta).Delete(1).Retain(1, nil).Delete(4),
+			index: 4,
+			want:  1,
+		},
+	}
+
+	for name, c := range cases {
+		if got := c.d.TransformPosition(c.index, c.priority); got != c.want {
+			t.Errorf("%s: got %d; want %d", name, got, c.want)
+		}
+	}
+
+}
+
+// sameDelta says if the two deltas have the same ops.
+func sameDelta(a, b *Delta) bool {
+	return len(a.Ops) == 0 && len(b.Ops) == 0 || reflect.DeepEqual(a.Ops, b.Ops)
+}
+
+func TestDelta_Invert(t *testing.T) {
+
+	base := new(Delta).Insert("123456", nil)
+
+	// Most of the cases are those of the quilljs/delta library.
+	cases := map[string]struct {
+		change, base, want *Delta
+	}{
+		"insert": {
+			change: new(Delta).Retain(2, nil).Insert("A", nil),
+			base:   base,
+			want:   new(Delta).Retain(2, nil).Delete(1),
+		},
+		"delete": {
+			change: new(Delta).Retain(2, nil).Delete(3),
+			base:   base,
+			want:   new(Delta).Retain(2, nil).Insert("345", nil),
+		},
+		"retain": {
+			change: new(Delta).Retain(2, nil).Retain(3, attrs{"bold": true}),
+			base:   base,
+			want:   new(Delta).Retain(2, nil).Retain(3, attrs{"bold": nil}),
+		},
+		"retain on different attributes": {
+			change: new(Delta).Retain(4, attrs{"italic": true}),
+			base:   new(Delta).Insert("123", nil).InsertEmbed("image", "a.png", attrs{"bold": true}),
+			want:   new(Delta).Retain(4, attrs{"italic": nil}),
+		},
+		"retain removing attributes": {
+			change: new(Delta).Retain(3, attrs{"bold": nil, "color": "red"}),
+			base:   new(Delta).Insert("12", attrs{"bold": true, "color": "blue"}).Insert("3", nil),
+			want:   new(Delta).Retain(2, attrs{"bold": true, "color": "blue"}).Retain(1, attrs{"bold": nil, "color": nil}),
+		},
+		"delete of formatted text and embeds": {
+			change: new(Delta).Delete(3),
+			base:   new(Delta).Insert("1", attrs{"bold": true}).InsertEmbed("image", "a.png", nil).Insert("3\n", nil),
+			want:   new(Delta).Insert("1", attrs{"bold": true}).InsertEmbed("image", "a.png", nil).Insert("3", nil),
+		},
+	}
+
+	for name, c := range cases {
+		got := c.change.Invert(c.base)
+		if !sameDelta(got, c.want) {
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(c.want)
+			t.Errorf("%s: got %s; want %s", name, gotJSON, wantJSON)
+		}
+		if doc := c.base.Compose(c.change).Compose(got); !sameDelta(doc, c.base) {
+			t.Errorf("%s: the inverted change does not restore the base", name)
+		}
+	}
+
+	// A combination of all kinds of ops is undone.
+	change := new(Delta).Retain(2, nil).Delete(2).Insert("AB", attrs{"italic": true}).
+		Retain(2, attrs{"italic": nil, "bold": true}).Retain(2, attrs{"color": "red"}).Delete(1)
+	base = new(Delta).Insert("123", attrs{"bold": true}).Insert("456", attrs{"italic": true}).
+		Insert("789", attrs{"color": "red", "bold": true})
+	if doc := base.Compose(change).Compose(change.Invert(base)); !sameDelta(doc, base) {
+		docJSON, _ := json.Marshal(doc)
+		t.Errorf("the inverted change does not restore the base; got %s", docJSON)
+	}
+
+}
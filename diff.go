@@ -0,0 +1,293 @@
+package quill
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Diff takes two versions of a document, each a Delta array of insert operations, and returns the change that turns a
+// into b.
+func Diff(a, b []byte) (*Delta, error) {
+	var da, db Delta
+	if err := json.Unmarshal(a, &da); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &db); err != nil {
+		return nil, err
+	}
+	return da.Diff(&db)
+}
+
+// Diff returns the smallest change that turns the document d into the document other, so that composing d with the
+// change gives other. Both must have only inserts; otherwise ErrNotDocument is returned.
+//
+// The text of the two documents is compared first, each embed counting as a single character, and then the attributes
+// of the text that is kept, so a change of formatting alone is a retain with the new attributes. An attribute that is
+// removed is set to nil.
+func (d *Delta) Diff(other *Delta) (*Delta, error) {
+
+	a, err := d.diffRunes()
+	if err != nil {
+		return nil, err
+	}
+	b, err := other.diffRunes()
+	if err != nil {
+		return nil, err
+	}
+
+	it, otherIt := deltaIterator{ops: d.Ops}, deltaIterator{ops: other.Ops}
+	out := new(Delta)
+	ai, bi := 0, 0
+
+	for _, part := range myersDiff(a, b) {
+		var length int
+		switch part.kind {
+		case diffInsert:
+			length = runesUTF16Len(b[bi : bi+part.n])
+			bi += part.n
+		default:
+			length = runesUTF16Len(a[ai : ai+part.n])
+			ai += part.n
+			if part.kind == diffEqual {
+				bi += part.n
+			}
+		}
+		for length > 0 {
+			var opLength int
+			switch part.kind {
+			case diffInsert:
+				opLength = minInt(otherIt.peekLength(), length)
+				out.Push(otherIt.next(opLength))
+			case diffDelete:
+				opLength = minInt(it.peekLength(), length)
+				it.next(opLength)
+				out.Delete(opLength)
+			case diffEqual:
+				opLength = minInt(minInt(it.peekLength(), otherIt.peekLength()), length)
+				thisOp, otherOp := it.next(opLength), otherIt.next(opLength)
+				if reflect.DeepEqual(thisOp.Insert, otherOp.Insert) {
+					out.Retain(opLength, diffAttributes(thisOp.Attributes, otherOp.Attributes))
+				} else {
+					// An embed is not the same as the character that stands in for it, or as another embed.
+					out.Push(otherOp).Delete(opLength)
+				}
+			}
+			length -= opLength
+		}
+	}
+
+	return out.Chop(), nil
+
+}
+
+// embedRune stands in for an embed in the text that is compared by Diff.
+const embedRune = 0
+
+// diffRunes returns the text of a document, with embedRune for each embed.
+func (d *Delta) diffRunes() ([]rune, error) {
+	var text []rune
+	for i := range d.Ops {
+		switch ins := d.Ops[i].Insert.(type) {
+		case nil:
+			return nil, ErrNotDocument
+		case string:
+			text = append(text, []rune(ins)...)
+		default:
+			text = append(text, embedRune)
+		}
+	}
+	return text, nil
+}
+
+// diffAttributes returns the attributes that a retain sets to turn the attributes a into b.
+func diffAttributes(a, b map[string]interface{}) map[string]interface{} {
+	attrs := make(map[string]interface{})
+	for k, v := range b {
+		if av, ok := a[k]; !ok || !reflect.DeepEqual(av, v) {
+			attrs[k] = v
+		}
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			attrs[k] = nil
+		}
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	return attrs
+}
+
+// The kinds of diffPart.
+const (
+	diffEqual = iota
+	diffInsert
+	diffDelete
+)
+
+// A diffPart is a run of n runes that are kept, inserted or deleted.
+type diffPart struct {
+	kind int
+	n    int
+}
+
+// maxDiffEdits bounds the number of edits that each search for a middle snake looks at. When two texts differ by more
+// than that, the part in which no snake was found is written as a deletion and an insertion, which is correct if not
+// the smallest change, so that the time a diff takes stays bounded.
+const maxDiffEdits = 1 << 12
+
+// myersDiff returns an edit script that turns a into b, found with the linear-space version of Eugene Myers' O(ND)
+// algorithm: the middle snake of the shortest edit script is found by searching from both ends at once, and the parts
+// before and after it are diffed in turn. It is the shortest edit script unless the texts differ by more than
+// maxDiffEdits.
+func myersDiff(a, b []rune) []diffPart {
+	var dl diffList
+	dl.diff(a, b)
+	return dl
+}
+
+// A diffList is an edit script being built up.
+type diffList []diffPart
+
+// add adds n runes of the kind to the end of the script.
+func (dl *diffList) add(kind, n int) {
+	if n == 0 {
+		return
+	}
+	if l := len(*dl); l > 0 && (*dl)[l-1].kind == kind {
+		(*dl)[l-1].n += n
+		return
+	}
+	*dl = append(*dl, diffPart{kind, n})
+}
+
+// diff adds the edits that turn a into b.
+func (dl *diffList) diff(a, b []rune) {
+
+	// The common prefix and suffix are taken out first, since they are often most of the text.
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	a, b = a[pre:len(a)-suf], b[pre:len(b)-suf]
+
+	dl.add(diffEqual, pre)
+	switch x, y, ok := diffBisect(a, b); {
+	case len(a) == 0 || len(b) == 0 || !ok:
+		dl.add(diffDelete, len(a))
+		dl.add(diffInsert, len(b))
+	default:
+		dl.diff(a[:x], b[:y])
+		dl.diff(a[x:], b[y:])
+	}
+	dl.add(diffEqual, suf)
+
+}
+
+// diffBisect finds the middle snake of the shortest edit script that turns a into b, searching forward from the start
+// and backward from the end until the paths meet, and returns the point at which to split a and b. It returns false if
+// the texts have nothing in common, or if no snake is found within maxDiffEdits edits. Only the furthest point on each
+// diagonal is kept, so the space used is linear.
+func diffBisect(a, b []rune) (int, int, bool) {
+
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return 0, 0, false
+	}
+
+	maxD := (n + m + 1) / 2
+	if maxD > maxDiffEdits {
+		maxD = maxDiffEdits
+	}
+	offset := maxD
+	size := 2*maxD + 2
+
+	// v1 and v2 hold, for each diagonal k (at index k+offset), how far along a the furthest forward and backward
+	// paths get, or -1 if the diagonal has not been reached.
+	v1, v2 := make([]int, size), make([]int, size)
+	for i := range v1 {
+		v1[i], v2[i] = -1, -1
+	}
+	v1[offset+1], v2[offset+1] = 0, 0
+
+	delta := n - m
+	front := delta%2 != 0 // If the difference in length is odd, the forward path meets the backward one.
+
+	// The diagonals that went off the edge of the grid are no longer searched.
+	var k1start, k1end, k2start, k2end int
+
+	for d := 0; d < maxD; d++ {
+
+		for k1 := -d + k1start; k1 <= d-k1end; k1 += 2 {
+			i := offset + k1
+			var x1 int
+			if k1 == -d || k1 != d && v1[i-1] < v1[i+1] {
+				x1 = v1[i+1]
+			} else {
+				x1 = v1[i-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+			v1[i] = x1
+			switch {
+			case x1 > n:
+				k1end += 2
+			case y1 > m:
+				k1start += 2
+			case front:
+				if j := offset + delta - k1; j >= 0 && j < size && v2[j] != -1 && x1 >= n-v2[j] {
+					return x1, y1, true
+				}
+			}
+		}
+
+		for k2 := -d + k2start; k2 <= d-k2end; k2 += 2 {
+			i := offset + k2
+			var x2 int
+			if k2 == -d || k2 != d && v2[i-1] < v2[i+1] {
+				x2 = v2[i+1]
+			} else {
+				x2 = v2[i-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && a[n-x2-1] == b[m-y2-1] {
+				x2++
+				y2++
+			}
+			v2[i] = x2
+			switch {
+			case x2 > n:
+				k2end += 2
+			case y2 > m:
+				k2start += 2
+			case !front:
+				if j := offset + delta - k2; j >= 0 && j < size && v1[j] != -1 {
+					x1 := v1[j]
+					if y1 := offset + x1 - j; x1 >= n-x2 {
+						return x1, y1, true
+					}
+				}
+			}
+		}
+
+	}
+
+	return 0, 0, false
+
+}
+
+// runesUTF16Len returns the length of the runes in UTF-16 code units.
+func runesUTF16Len(rs []rune) int {
+	n := 0
+	for _, r := range rs {
+		n += utf16RuneLen(r)
+	}
+	return n
+}
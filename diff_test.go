@@ -0,0 +1,243 @@
+package quill
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDelta_Diff(t *testing.T) {
+
+	bold := attrs{"bold": true}
+
+	// Most of the cases are those of the quilljs/delta library.
+	cases := map[string]struct {
+		a, b, want *Delta
+	}{
+		"insert": {
+			a:    new(Delta).Insert("A", nil),
+			b:    new(Delta).Insert("AB", nil),
+			want: new(Delta).Retain(1, nil).Insert("B", nil),
+		},
+		"delete": {
+			a:    new(Delta).Insert("AB", nil),
+			b:    new(Delta).Insert("A", nil),
+			want: new(Delta).Retain(1, nil).Delete(1),
+		},
+		"retain": {
+			a:    new(Delta).Insert("A", nil),
+			b:    new(Delta).Insert("A", nil),
+			want: new(Delta),
+		},
+		"format": {
+			a:    new(Delta).Insert("A", nil),
+			b:    new(Delta).Insert("A", bold),
+			want: new(Delta).Retain(1, bold),
+		},
+		"remove format": {
+			a:    new(Delta).Insert("AB", attrs{"bold": true, "color": "red"}),
+			b:    new(Delta).Insert("A", attrs{"color": "red"}).Insert("B", attrs{"color": "red", "bold": true}),
+			want: new(Delta).Retain(1, attrs{"bold": nil}),
+		},
+		"object attributes": {
+			a:    new(Delta).Insert("A", attrs{"font": attrs{"family": "Helvetica", "size": "15px"}}),
+			b:    new(Delta).Insert("A", attrs{"font": attrs{"family": "Helvetica", "size": "15px"}}),
+			want: new(Delta),
+		},
+		"embed match": {
+			a:    new(Delta).InsertEmbed("image", "http://quilljs.com", nil),
+			b:    new(Delta).InsertEmbed("image", "http://quilljs.com", nil),
+			want: new(Delta),
+		},
+		"embed mismatch": {
+			a:    new(Delta).InsertEmbed("image", "http://quilljs.com", nil),
+			b:    new(Delta).InsertEmbed("image", "http://github.com", nil),
+			want: new(Delta).InsertEmbed("image", "http://github.com", nil).Delete(1),
+		},
+		"embed format": {
+			a:    new(Delta).Insert("x", nil).InsertEmbed("image", "http://quilljs.com", nil),
+			b:    new(Delta).Insert("x", nil).InsertEmbed("image", "http://quilljs.com", attrs{"width": "100"}),
+			want: new(Delta).Retain(1, nil).Retain(1, attrs{"width": "100"}),
+		},
+		"embed false positive": {
+			a:    new(Delta).InsertEmbed("image", "http://quilljs.com", nil),
+			b:    new(Delta).Insert("\x00", nil),
+			want: new(Delta).Insert("\x00", nil).Delete(1),
+		},
+		"inconvenient indexes": {
+			a:    new(Delta).Insert("12", bold).Insert("34", attrs{"italic": true}),
+			b:    new(Delta).Insert("123", attrs{"color": "red"}),
+			want: new(Delta).Retain(2, attrs{"bold": nil, "color": "red"}).Retain(1, attrs{"italic": nil, "color": "red"}).Delete(1),
+		},
+		"surrogate pairs": {
+			a:    new(Delta).Insert("a\U0001F600b\U0001F600c", nil),
+			b:    new(Delta).Insert("a\U0001F601b\U0001F600", nil),
+			want: new(Delta).Retain(1, nil).Insert("\U0001F601", nil).Delete(2).Retain(3, nil).Delete(1),
+		},
+	}
+
+	for name, c := range cases {
+		got, err := c.a.Diff(c.b)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if !sameDelta(got, c.want) {
+			gotJSON, _ := json.Marshal(got)
+			wantJSON, _ := json.Marshal(c.want)
+			t.Errorf("%s: got %s; want %s", name, gotJSON, wantJSON)
+		}
+	}
+
+	doc := new(Delta).Insert("A", nil)
+	change := new(Delta).Retain(1, nil).Insert("B", nil)
+	if _, err := doc.Diff(change); err != ErrNotDocument {
+		t.Errorf("got error %v for a diff with a change; want ErrNotDocument", err)
+	}
+	if _, err := change.Diff(doc); err != ErrNotDocument {
+		t.Errorf("got error %v for a diff of a change; want ErrNotDocument", err)
+	}
+
+}
+
+func TestDiff(t *testing.T) {
+
+	// Composing each document with its diff to every other document gives the other document.
+	files, err := filepath.Glob("./testdata/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := make([][]byte, len(files))
+	for i, f := range files {
+		if docs[i], err = ioutil.ReadFile(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := range docs {
+		for j := range docs {
+			change, err := Diff(docs[i], docs[j])
+			if err != nil {
+				t.Fatalf("%s to %s: %s", files[i], files[j], err)
+			}
+			var a, b Delta
+			json.Unmarshal(docs[i], &a)
+			json.Unmarshal(docs[j], &b)
+			if got := a.Compose(change); !sameDelta(got, &b) {
+				changeJSON, _ := json.Marshal(change)
+				t.Errorf("%s to %s: bad diff %s", files[i], files[j], changeJSON)
+			}
+			if i == j && len(change.Ops) > 0 {
+				t.Errorf("%s: the diff with itself is not empty", files[i])
+			}
+		}
+	}
+
+	// A change of formatting alone is a retain.
+	change, err := Diff([]byte(`[{"insert":"Title\n"}]`), []byte(`[{"insert":"Title"},{"insert":"\n","attributes":{"header":1}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := json.Marshal(change); string(got) != `[{"retain":5},{"retain":1,"attributes":{"header":1}}]` {
+		t.Errorf("bad diff; got: %s", got)
+	}
+
+}
+
+func TestDelta_Diff_large(t *testing.T) {
+
+	// Two long documents that have little in common, each with a few paragraphs that the other also has.
+	rnd := rand.New(rand.NewSource(1))
+	text := func(letters string, n int) string {
+		var sb strings.Builder
+		for i := 0; i < n; i++ {
+			if i%500 == 0 {
+				sb.WriteString("A paragraph that both documents have.\n")
+			}
+			sb.WriteByte(letters[rnd.Intn(len(letters))])
+		}
+		return sb.String()
+	}
+	a := new(Delta).Insert(text("abcdefghij", 40000), nil)
+	b := new(Delta).Insert(text("fghijklmno", 40000), attrs{"bold": true})
+
+	change, err := a.Diff(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Compose(change); !sameDelta(got, b) {
+		t.Errorf("the change does not turn the first document into the second")
+	}
+
+	// Documents with nothing in common are a deletion and an insertion.
+	c := new(Delta).Insert(strings.Repeat("x", 40000), nil)
+	d := new(Delta).Insert(strings.Repeat("y", 40000), nil)
+	change, err = c.Diff(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := new(Delta).Insert(strings.Repeat("y", 40000), nil).Delete(40000); !sameDelta(change, want) {
+		t.Errorf("bad diff of documents with nothing in common")
+	}
+
+}
+
+func TestMyersDiff(t *testing.T) {
+
+	// The edit script is the shortest, which is found by comparing the length of the longest common subsequence.
+	rnd := rand.New(rand.NewSource(2))
+	for i := 0; i < 200; i++ {
+		a, b := make([]rune, rnd.Intn(40)), make([]rune, rnd.Intn(40))
+		for j := range a {
+			a[j] = rune('a' + rnd.Intn(3))
+		}
+		for j := range b {
+			b[j] = rune('a' + rnd.Intn(3))
+		}
+		var kept, ai, bi int
+		for _, p := range myersDiff(a, b) {
+			switch p.kind {
+			case diffEqual:
+				if string(a[ai:ai+p.n]) != string(b[bi:bi+p.n]) {
+					t.Fatalf("%q to %q: kept runes differ", string(a), string(b))
+				}
+				kept += p.n
+				ai += p.n
+				bi += p.n
+			case diffDelete:
+				ai += p.n
+			case diffInsert:
+				bi += p.n
+			}
+		}
+		if ai != len(a) || bi != len(b) {
+			t.Fatalf("%q to %q: the script does not cover the texts", string(a), string(b))
+		}
+		if want := lcsLength(a, b); kept != want {
+			t.Errorf("%q to %q: kept %d runes; want %d", string(a), string(b), kept, want)
+		}
+	}
+
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []rune) int {
+	prev, cur := make([]int, len(b)+1), make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
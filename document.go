@@ -0,0 +1,310 @@
+package quill
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// A Document is a Delta split up into its lines, each of which is a Block. It is the structure from which every kind of
+// output is written, and it can be inspected or changed before rendering.
+type Document struct {
+	Blocks []*Block
+}
+
+// A Block is a single line of a document: the inserts up to a "\n". The attributes of a Block are those of its
+// terminating "\n", which carries the block-level formats such as "header" or "list".
+//
+// An embed that is a block of its own (see BlockEmbed), such as a divider, is split off of the line into a Block, and
+// the parts of the line before and after it are Blocks with the attributes of the line. The Block of an embed that ends
+// the line has the attributes of the line that are written as classes or styles (such as "align"); other embeds have
+// none.
+type Block struct {
+	Attrs   map[string]string // the attributes of the terminating "\n"
+	Inlines []*Op             // the text runs and embeds of the line, without any "\n"
+	cont    bool              // the line goes on in the next Block, having been split at a block embed
+}
+
+// HasAttr says if the Block has the attribute set to a non-blank value.
+func (b *Block) HasAttr(attr string) bool {
+	return b.Attrs[attr] != ""
+}
+
+// Text returns the text of the Block, leaving out any embeds.
+func (b *Block) Text() string {
+	var sb strings.Builder
+	for _, o := range b.Inlines {
+		if o.Type == "text" {
+			sb.WriteString(o.Data)
+		}
+	}
+	return sb.String()
+}
+
+// GroupKind says what kind of blocks make up a Group.
+type GroupKind uint8
+
+const (
+	SingleGroup GroupKind = iota // a block that is not grouped with others
+	ListGroup                    // consecutive list items (of any type and indent)
+	CodeGroup                    // consecutive lines of a code block
+)
+
+// A Group is a run of consecutive blocks that belong together, such as the items of a list.
+type Group struct {
+	Kind   GroupKind
+	Blocks []*Block
+}
+
+// groupKind says what kind of Group the block belongs to.
+func (b *Block) groupKind() GroupKind {
+	switch {
+	case b.HasAttr("list"):
+		return ListGroup
+	case b.HasAttr("code-block"):
+		return CodeGroup
+	}
+	return SingleGroup
+}
+
+// Groups splits the blocks of the Document into groups: each list and each code block is a single Group, and every
+// other block is a Group by itself.
+func (d *Document) Groups() []Group {
+	var groups []Group
+	for _, b := range d.Blocks {
+		k := b.groupKind()
+		if n := len(groups); k != SingleGroup && n > 0 && groups[n-1].Kind == k {
+			groups[n-1].Blocks = append(groups[n-1].Blocks, b)
+			continue
+		}
+		groups = append(groups, Group{Kind: k, Blocks: []*Block{b}})
+	}
+	return groups
+}
+
+// MarshalJSON writes the Document as a Delta: a JSON array of insert operations in which consecutive text inserts that
+// have the same attributes are joined together, as Quill does. Attributes with a blank value are left out, the "y" of
+// boolean attributes and embeds is written as true, and the numeric header and indent values are written as numbers.
+func (d *Document) MarshalJSON() ([]byte, error) {
+
+	ops := make([]deltaInsert, 0, len(d.Blocks)*2)
+	push := func(ins interface{}, attrs map[string]string) {
+		attrs = nonBlank(attrs)
+		if s, ok := ins.(string); ok && len(ops) > 0 {
+			last := &ops[len(ops)-1]
+			if ls, ok := last.Insert.(string); ok && sameAttrs(attrs, last.attrs) {
+				last.Insert = ls + s
+				return
+			}
+		}
+		ops = append(ops, deltaInsert{Insert: ins, Attributes: deltaAttrs(attrs), attrs: attrs})
+	}
+
+	for _, b := range d.Blocks {
+		for _, o := range b.Inlines {
+			if o.Type == "text" {
+				push(o.Data, o.Attrs)
+			} else if o.Data == "y" {
+				push(map[string]bool{o.Type: true}, o.Attrs) // an embed without a value, such as a divider
+			} else {
+				push(map[string]string{o.Type: o.Data}, o.Attrs)
+			}
+		}
+		if !b.cont {
+			push("\n", b.Attrs)
+		}
+	}
+
+	return json.Marshal(ops)
+
+}
+
+// A deltaInsert is an insert operation of a Delta as it is written out.
+type deltaInsert struct {
+	Insert     interface{}            `json:"insert"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	attrs      map[string]string      // the non-blank attributes, for comparison
+}
+
+// deltaAttrs converts the non-blank attributes of an Op to the values they have in a Delta.
+func deltaAttrs(attrs map[string]string) map[string]interface{} {
+	if len(attrs) == 0 {
+		return nil
+	}
+	da := make(map[string]interface{}, len(attrs))
+	for k, v := range attrs {
+		switch {
+		case v == "y":
+			da[k] = true
+		case k == "header" || k == "indent":
+			if n, err := strconv.Atoi(v); err == nil {
+				da[k] = n
+				continue
+			}
+			da[k] = v
+		default:
+			da[k] = v
+		}
+	}
+	return da
+}
+
+// nonBlank returns the attributes that have a value.
+func nonBlank(attrs map[string]string) map[string]string {
+	nb := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		if v != "" {
+			nb[k] = v
+		}
+	}
+	return nb
+}
+
+// Parse takes a Delta array of insert operations and splits it into the blocks of a Document.
+func Parse(ops []byte) (*Document, error) {
+	return defaultRenderer.Parse(ops)
+}
+
+// Parse takes a Delta array of insert operations and splits it into the blocks of a Document, enforcing the limits set
+// on the Renderer.
+func (r *Renderer) Parse(ops []byte) (*Document, error) {
+
+	raw, err := r.decode(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := new(Document)
+	bs := blockSplitter{r: r, emit: func(b *Block) error {
+		doc.Blocks = append(doc.Blocks, b)
+		return nil
+	}}
+
+	for i := range raw {
+		o, err := raw[i].op()
+		if err != nil {
+			return nil, err
+		}
+		bs.add(o) // The emit function does not return errors.
+	}
+	bs.end()
+
+	return doc, nil
+
+}
+
+// A blockSplitter splits the ops of a Delta into blocks, giving each Block to emit as soon as it is complete. The
+// formats of r say which embeds are blocks of their own.
+type blockSplitter struct {
+	r    *Renderer
+	cur  *Block
+	emit func(*Block) error
+}
+
+// add adds an op to the current block, ending the block at each "\n".
+func (bs *blockSplitter) add(o *Op) error {
+
+	if o.Type != "text" || strings.IndexByte(o.Data, '\n') == -1 {
+		bs.inline(o)
+		return nil
+	}
+
+	data := o.Data
+	for {
+		i := strings.IndexByte(data, '\n')
+		if i == -1 {
+			break
+		}
+		if i > 0 {
+			bs.inline(&Op{Data: data[:i], Type: "text", Attrs: copyAttrs(o.Attrs)})
+		}
+		if bs.cur == nil {
+			bs.cur = new(Block)
+		}
+		bs.cur.Attrs = copyAttrs(o.Attrs)
+		if err := bs.flush(); err != nil {
+			return err
+		}
+		data = data[i+1:]
+	}
+
+	if data != "" {
+		bs.inline(&Op{Data: data, Type: "text", Attrs: copyAttrs(o.Attrs)})
+	}
+
+	return nil
+
+}
+
+// inline adds an inline op to the current block.
+func (bs *blockSplitter) inline(o *Op) {
+	if o.Type == "text" && o.Data == "" {
+		return
+	}
+	if bs.cur == nil {
+		bs.cur = new(Block)
+	}
+	bs.cur.Inlines = append(bs.cur.Inlines, o)
+}
+
+// flush gives the current block to emit. If the block holds embeds that are blocks of their own, it is split around
+// each of them, and the embeds are given to emit as blocks of their own.
+func (bs *blockSplitter) flush() error {
+
+	b := bs.cur
+	bs.cur = nil
+	if b.Attrs == nil {
+		b.Attrs = make(map[string]string)
+	}
+
+	start := 0
+	for i, in := range b.Inlines {
+		if !bs.r.isBlockEmbed(in) {
+			continue
+		}
+		if i > start {
+			part := &Block{Attrs: copyAttrs(b.Attrs), Inlines: b.Inlines[start:i:i], cont: true}
+			if err := bs.emit(part); err != nil {
+				return err
+			}
+		}
+		embed := &Block{Attrs: make(map[string]string), Inlines: b.Inlines[i : i+1 : i+1], cont: i < len(b.Inlines)-1}
+		if !embed.cont {
+			for attr := range bs.r.embedFormats(b.Attrs) {
+				embed.Attrs[attr] = b.Attrs[attr] // The embed ends the line, so it has the classes and styles of the line.
+			}
+		}
+		if err := bs.emit(embed); err != nil {
+			return err
+		}
+		start = i + 1
+	}
+
+	switch {
+	case start == 0:
+		return bs.emit(b)
+	case start < len(b.Inlines):
+		b.Inlines = b.Inlines[start:]
+		return bs.emit(b)
+	}
+	return nil // A line that ends with a block embed does not add an empty block after it.
+
+}
+
+// end ends the Delta. A Delta should end with a "\n", but if the last line is not terminated then it is taken as a
+// block without any attributes.
+func (bs *blockSplitter) end() error {
+	if bs.cur == nil {
+		return nil
+	}
+	return bs.flush()
+}
+
+// copyAttrs returns a copy of the attributes map.
+func copyAttrs(attrs map[string]string) map[string]string {
+	c := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		c[k] = v
+	}
+	return c
+}
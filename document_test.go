@@ -0,0 +1,195 @@
+package quill
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+
+	ops := `[{"insert":"Title"},{"attributes":{"header":1},"insert":"\n"},
+		{"insert":"plain "},{"attributes":{"bold":true},"insert":"bold"},{"insert":{"image":"a.png"}},{"insert":"\nitem1"},
+		{"attributes":{"list":"bullet"},"insert":"\n"},{"insert":"item2"},{"attributes":{"list":"ordered","indent":1},"insert":"\n"},
+		{"insert":"x = 1"},{"attributes":{"code-block":true},"insert":"\n"},{"insert":"trailing"}]`
+
+	doc, err := Parse([]byte(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Document{Blocks: []*Block{
+		{
+			Attrs:   map[string]string{"header": "1"},
+			Inlines: []*Op{{Data: "Title", Type: "text", Attrs: map[string]string{}}},
+		},
+		{
+			Attrs: map[string]string{},
+			Inlines: []*Op{
+				{Data: "plain ", Type: "text", Attrs: map[string]string{}},
+				{Data: "bold", Type: "text", Attrs: map[string]string{"bold": "y"}},
+				{Data: "a.png", Type: "image", Attrs: map[string]string{}},
+			},
+		},
+		{
+			Attrs:   map[string]string{"list": "bullet"},
+			Inlines: []*Op{{Data: "item1", Type: "text", Attrs: map[string]string{}}},
+		},
+		{
+			Attrs:   map[string]string{"list": "ordered", "indent": "1"},
+			Inlines: []*Op{{Data: "item2", Type: "text", Attrs: map[string]string{}}},
+		},
+		{
+			Attrs:   map[string]string{"code-block": "y"},
+			Inlines: []*Op{{Data: "x = 1", Type: "text", Attrs: map[string]string{}}},
+		},
+		{
+			Attrs:   map[string]string{},
+			Inlines: []*Op{{Data: "trailing", Type: "text", Attrs: map[string]string{}}},
+		},
+	}}
+
+	if !reflect.DeepEqual(doc, want) {
+		for i, b := range doc.Blocks {
+			t.Logf("block %d: %+v", i, *b)
+			for _, in := range b.Inlines {
+				t.Logf("  inline: %+v", *in)
+			}
+		}
+		t.Fatalf("bad document")
+	}
+
+	if got := doc.Blocks[1].Text(); got != "plain bold" {
+		t.Errorf("bad block text %q", got)
+	}
+
+	groups := doc.Groups()
+	kinds := make([]GroupKind, len(groups))
+	for i := range groups {
+		kinds[i] = groups[i].Kind
+	}
+	if !reflect.DeepEqual(kinds, []GroupKind{SingleGroup, SingleGroup, ListGroup, CodeGroup, SingleGroup}) {
+		t.Errorf("bad grouping: %v", kinds)
+	}
+	if len(groups[2].Blocks) != 2 {
+		t.Errorf("list group has %d blocks", len(groups[2].Blocks))
+	}
+
+	if _, err = Parse([]byte(`[{"attributes":{"bold":true}}]`)); err == nil {
+		t.Errorf("no error for an op without an insert")
+	}
+
+}
+
+func TestRenderer_RenderDocument(t *testing.T) {
+
+	for _, n := range []string{"ops1", "list4", "code2"} {
+
+		ops, err := ioutil.ReadFile("./testdata/" + n + ".json")
+		if err != nil {
+			t.Fatalf("could not read %s.json; %s", n, err)
+		}
+
+		want, err := Render(ops)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		doc, err := Parse(ops)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := defaultRenderer.RenderDocument(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(want, got) {
+			t.Errorf("rendering the document of %s differs; got: %s", n, got)
+		}
+
+	}
+
+	// A Document can be changed before rendering.
+	doc, err := Parse([]byte(`[{"insert":"Title\n"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc.Blocks[0].Attrs["header"] = "2"
+	got, err := defaultRenderer.RenderDocument(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "<h2>Title</h2>" {
+		t.Errorf("bad rendering of changed document; got: %s", got)
+	}
+
+}
+
+func TestDocument_MarshalJSON(t *testing.T) {
+
+	for _, n := range []string{"ops1", "list4", "code2", "nested"} {
+		ops, err := ioutil.ReadFile("./testdata/" + n + ".json")
+		if err != nil {
+			t.Fatalf("could not read %s.json; %s", n, err)
+		}
+		doc, err := Parse(ops)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, got, ops) {
+			t.Errorf("the document of %s does not marshal to its Delta; got: %s", n, got)
+		}
+	}
+
+	// Blank attributes are left out, and so text runs that differ only in them are joined.
+	doc, err := Parse([]byte(`[{"insert":"a","attributes":{"bold":false}},{"insert":"b\n"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `[{"insert":"ab\n"}]` {
+		t.Errorf("bad JSON; got: %s", got)
+	}
+
+	// A line split at a block embed is joined again.
+	ops := `[{"insert":"a"},{"insert":{"divider":true}},{"insert":"b"},{"insert":"\n","attributes":{"header":2}}]`
+	if doc, err = Parse([]byte(ops)); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Blocks) != 3 || len(doc.Blocks[1].Attrs) != 0 || doc.Blocks[2].Attrs["header"] != "2" {
+		t.Errorf("the line is not split at the divider: %+v", doc.Blocks)
+	}
+	if got, err = json.Marshal(doc); err != nil {
+		t.Fatal(err)
+	}
+	if !sameJSON(t, got, []byte(ops)) {
+		t.Errorf("the split line does not marshal to its Delta; got: %s", got)
+	}
+
+	// An embed that ends a line keeps the alignment of the line.
+	ops = `[{"insert":{"video":"https://youtu.be/abc"}},{"insert":"\n","attributes":{"align":"center"}}]`
+	if doc, err = Parse([]byte(ops)); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Blocks) != 1 || !reflect.DeepEqual(doc.Blocks[0].Attrs, map[string]string{"align": "center"}) {
+		t.Errorf("the alignment of the video is lost: %+v", doc.Blocks)
+	}
+	if got, err = json.Marshal(doc); err != nil {
+		t.Fatal(err)
+	}
+	if !sameJSON(t, got, []byte(ops)) {
+		t.Errorf("the aligned video does not marshal to its Delta; got: %s", got)
+	}
+
+}
@@ -0,0 +1,305 @@
+package quill
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// WithEmailProfile sets up the Renderer to write HTML that email clients show as intended. The HTML has no classes:
+// sizes, alignments, indents, fonts and text direction are written as inline styles (with the values of QuillStyles
+// unless WithInlineStyles gives others), and indented list items are nested in plain lists rather than laid out with
+// classes or tables. Videos are written as links, formulas as their LaTeX source, and code blocks are not
+// highlighted. Options given after this one may change these settings.
+func WithEmailProfile() Option {
+	return func(r *Renderer) {
+		r.email = true
+		if r.styles == nil {
+			r.styles = QuillStyles()
+		}
+		r.nestedLists = true
+		r.highlight = NoHighlighting
+	}
+}
+
+// EmailOptions are the settings of a single email rendered with RenderEmail.
+type EmailOptions struct {
+	// Title is the title of the HTML document.
+	Title string
+
+	// AttachImages turns the images of the Delta into attachments that the HTML refers to with cid: URLs, so that they
+	// are shown without being loaded from the web. Images in data: URLs are decoded; images at other URLs are attached
+	// only if LoadImage is set.
+	AttachImages bool
+
+	// LoadImage returns the content and the media type (such as "image/png") of the image at the URL. An image that
+	// cannot be loaded keeps its URL.
+	LoadImage func(url string) (data []byte, mediaType string, err error)
+}
+
+// An Email is a Delta rendered as an email message: a complete HTML document, the same content as plain text, and any
+// images attached to the HTML.
+type Email struct {
+	HTML   []byte
+	Text   []byte
+	Images []EmailImage
+}
+
+// An EmailImage is an image that the HTML of an Email refers to as "cid:" followed by the ContentID.
+type EmailImage struct {
+	ContentID string // the ID of the image, without angle brackets
+	MediaType string
+	Data      []byte
+}
+
+// emailRenderer is used by the package-level RenderEmail.
+var emailRenderer = NewRenderer(WithEmailProfile())
+
+// RenderEmail takes a Delta array of insert operations and returns it as an email message, rendered with the built-in
+// settings and WithEmailProfile. The options may be nil.
+func RenderEmail(ops []byte, opts *EmailOptions) (*Email, error) {
+	return emailRenderer.RenderEmail(ops, opts)
+}
+
+// RenderEmail takes a Delta array of insert operations and returns it as an email message. The HTML is wrapped in a
+// complete document, and the plain text is what RenderText gives. Create the Renderer with WithEmailProfile to get
+// HTML that email clients can show. The options may be nil.
+func (r *Renderer) RenderEmail(ops []byte, opts *EmailOptions) (*Email, error) {
+	doc, err := r.Parse(ops)
+	if err != nil {
+		return nil, err
+	}
+	return r.RenderDocumentEmail(doc, opts)
+}
+
+// RenderDocumentEmail returns the Document as an email message. See RenderEmail.
+func (r *Renderer) RenderDocumentEmail(doc *Document, opts *EmailOptions) (*Email, error) {
+
+	if opts == nil {
+		opts = new(EmailOptions)
+	}
+
+	e := new(Email)
+
+	hr := r
+	if f := r.formats.Lookup("image"); opts.AttachImages && f != nil {
+		a := &imageAttacher{email: e, load: opts.LoadImage, ids: make(map[string]string)}
+		if err := a.setToken(); err != nil {
+			return nil, err
+		}
+		c := *r
+		c.formats = r.formats.Clone()
+		c.formats.Register("image", a.factory(f))
+		hr = &c
+	}
+
+	body, err := hr.RenderDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`<!DOCTYPE html><html><head><meta http-equiv="Content-Type" content="text/html; charset=utf-8">`)
+	buf.WriteString(`<meta name="viewport" content="width=device-width, initial-scale=1.0">`)
+	if opts.Title != "" {
+		buf.WriteString("<title>")
+		buf.WriteString(html.EscapeString(opts.Title))
+		buf.WriteString("</title>")
+	}
+	buf.WriteString("</head><body>")
+	buf.Write(body)
+	buf.WriteString("</body></html>")
+
+	e.HTML = buf.Bytes()
+	e.Text = r.RenderDocumentText(doc)
+
+	return e, nil
+
+}
+
+// An imageAttacher turns the images of an Email into attachments.
+type imageAttacher struct {
+	email *Email
+	load  func(string) ([]byte, string, error)
+	ids   map[string]string // the content ID of each image URL already attached
+	token string            // the random part of the content IDs, which makes them unique
+}
+
+// setToken sets the random part of the content IDs.
+func (a *imageAttacher) setToken() error {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	a.token = hex.EncodeToString(b)
+	return nil
+}
+
+// factory returns a FormatFactory that makes the image format with f and then points the image at its attachment.
+func (a *imageAttacher) factory(f FormatFactory) FormatFactory {
+	return func(o *Op, r *Renderer) Formatter {
+		fm := f(o, r)
+		if imf, ok := fm.(*imageFormat); ok && imf.src != "" {
+			if id := a.attach(imf.src); id != "" {
+				imf.src = "cid:" + id
+			}
+		}
+		return fm
+	}
+}
+
+// attach attaches the image at src, if it has not been attached yet, and returns its content ID. If the image cannot
+// be attached, it returns "".
+func (a *imageAttacher) attach(src string) string {
+
+	if id, ok := a.ids[src]; ok {
+		return id
+	}
+
+	load := a.load
+	if strings.HasPrefix(strings.ToLower(src), "data:") {
+		load = decodeDataURL
+	}
+
+	id := ""
+	if load == nil {
+		a.ids[src] = id
+		return id
+	}
+
+	data, mediaType, err := load(src)
+	if err == nil && strings.HasPrefix(mediaType, "image/") {
+		id = "image" + strconv.Itoa(len(a.email.Images)+1) + "." + a.token + "@quill"
+		a.email.Images = append(a.email.Images, EmailImage{ContentID: id, MediaType: mediaType, Data: data})
+	}
+	a.ids[src] = id // Do not try again to attach an image that failed.
+
+	return id
+
+}
+
+// decodeDataURL returns the content and media type of a data: URL.
+func decodeDataURL(src string) ([]byte, string, error) {
+
+	i := strings.IndexByte(src, ',')
+	if i == -1 {
+		return nil, "", errors.New("quill: malformed data URL")
+	}
+	meta, payload := src[len("data:"):i], src[i+1:]
+
+	b64 := false
+	if strings.HasSuffix(strings.ToLower(meta), ";base64") {
+		b64 = true
+		meta = meta[:len(meta)-len(";base64")]
+	}
+
+	mediaType, _, err := mime.ParseMediaType(meta)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if b64 {
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(payload), ""))
+		return data, mediaType, err
+	}
+	data, err := url.PathUnescape(payload)
+	return []byte(data), mediaType, err
+
+}
+
+// Multipart returns the Email as the body of a multipart/alternative message, along with the value of the
+// Content-Type header that goes with the body. The plain text comes before the HTML, so that clients that can show
+// HTML pick it. If the Email has images, the HTML and the images are put together in a multipart/related part.
+func (e *Email) Multipart() (contentType string, body []byte, err error) {
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	if err := writeTextPart(mw, "text/plain; charset=utf-8", e.Text); err != nil {
+		return "", nil, err
+	}
+
+	if len(e.Images) == 0 {
+		if err := writeTextPart(mw, "text/html; charset=utf-8", e.HTML); err != nil {
+			return "", nil, err
+		}
+	} else {
+		var related bytes.Buffer
+		rw := multipart.NewWriter(&related)
+		if err := writeTextPart(rw, "text/html; charset=utf-8", e.HTML); err != nil {
+			return "", nil, err
+		}
+		for _, im := range e.Images {
+			if err := writeImagePart(rw, im); err != nil {
+				return "", nil, err
+			}
+		}
+		if err := rw.Close(); err != nil {
+			return "", nil, err
+		}
+		ct := mime.FormatMediaType("multipart/related", map[string]string{"boundary": rw.Boundary(), "type": "text/html"})
+		p, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {ct}})
+		if err != nil {
+			return "", nil, err
+		}
+		if _, err := p.Write(related.Bytes()); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return "", nil, err
+	}
+
+	return mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()}), buf.Bytes(), nil
+
+}
+
+// writeTextPart writes a part of text in the quoted-printable encoding.
+func writeTextPart(mw *multipart.Writer, contentType string, text []byte) error {
+	p, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qw := quotedprintable.NewWriter(p)
+	if _, err := qw.Write(text); err != nil {
+		return err
+	}
+	return qw.Close()
+}
+
+// writeImagePart writes an image in the base64 encoding, in lines of 76 characters.
+func writeImagePart(mw *multipart.Writer, im EmailImage) error {
+	p, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {im.MediaType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Id":                {"<" + im.ContentID + ">"},
+		"Content-Disposition":       {"inline"},
+	})
+	if err != nil {
+		return err
+	}
+	enc := base64.StdEncoding.EncodeToString(im.Data)
+	for len(enc) > 76 {
+		if _, err := io.WriteString(p, enc[:76]+"\r\n"); err != nil {
+			return err
+		}
+		enc = enc[76:]
+	}
+	_, err = io.WriteString(p, enc+"\r\n")
+	return err
+}
@@ -0,0 +1,158 @@
+package quill
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+func TestRenderEmail(t *testing.T) {
+
+	ops := `[{"insert":"Hello","attributes":{"size":"large","font":"serif"}},{"insert":"\n","attributes":{"align":"center","header":1}},
+		{"insert":"one"},{"insert":"\n","attributes":{"list":"bullet"}},{"insert":"two"},{"insert":"\n","attributes":{"list":"bullet","indent":1}},
+		{"insert":"right"},{"insert":"\n","attributes":{"direction":"rtl","indent":1}},
+		{"insert":{"video":"https://youtu.be/abc"}},{"insert":{"formula":"x^2"}},{"insert":{"page-break":true}},
+		{"insert":{"image":"data:image/png;base64,iVBORw0KGgo="}},{"insert":{"image":"https://example.com/a.png"}},
+		{"insert":{"image":"https://example.com/missing.png"}},{"insert":{"image":"data:image/png;base64,iVBORw0KGgo="}},{"insert":"\n"}]`
+
+	e, err := RenderEmail([]byte(ops), &EmailOptions{
+		Title:        "Hi & bye",
+		AttachImages: true,
+		LoadImage: func(url string) ([]byte, string, error) {
+			if url == "https://example.com/a.png" {
+				return []byte("png"), "image/png", nil
+			}
+			return nil, "", errors.New("not found")
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(e.Images) != 2 {
+		t.Fatalf("got %d images; want 2", len(e.Images))
+	}
+	if !bytes.Equal(e.Images[0].Data, []byte("\x89PNG\r\n\x1a\n")) || e.Images[0].MediaType != "image/png" {
+		t.Errorf("bad image decoded from a data URL: %+v", e.Images[0])
+	}
+	if !bytes.Equal(e.Images[1].Data, []byte("png")) {
+		t.Errorf("bad image loaded: %+v", e.Images[1])
+	}
+
+	cid0, cid1 := "cid:"+e.Images[0].ContentID, "cid:"+e.Images[1].ContentID
+	want := `<!DOCTYPE html><html><head><meta http-equiv="Content-Type" content="text/html; charset=utf-8">` +
+		`<meta name="viewport" content="width=device-width, initial-scale=1.0"><title>Hi &amp; bye</title></head><body>` +
+		`<h1 style="text-align:center;"><span style="font-family:Georgia, Times New Roman, serif;"><span style="font-size:1.5em;">Hello</span></span></h1>` +
+		`<ul><li>one<ul><li>two</li></ul></li></ul><p style="direction:rtl;padding-right:3em;">right</p>` +
+		`<p><a href="https://youtu.be/abc" target="_blank" rel="nofollow noopener">https://youtu.be/abc</a>x^2</p>` +
+		`<div style="break-after:page;page-break-after:always"></div>` +
+		`<p><img src="` + cid0 + `" loading="lazy"><img src="` + cid1 + `" loading="lazy">` +
+		`<img src="https://example.com/missing.png" loading="lazy"><img src="` + cid0 + `" loading="lazy"></p></body></html>`
+	if string(e.HTML) != want {
+		t.Errorf("bad HTML\ngot:  %s\nwant: %s", e.HTML, want)
+	}
+	if strings.Contains(string(e.HTML), "class=") {
+		t.Errorf("classes written")
+	}
+
+	wantText := "Hello\n\n- one\n  * two\n\nright\n\nhttps://youtu.be/abcx^2\n\nhttps://example.com/a.pnghttps://example.com/missing.png\n"
+	if string(e.Text) != wantText {
+		t.Errorf("bad text: %q", e.Text)
+	}
+
+	// The parts can be read back.
+	ct, body, err := e.Multipart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, params, err := mime.ParseMediaType(ct)
+	if err != nil || mt != "multipart/alternative" {
+		t.Fatalf("bad content type %q", ct)
+	}
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+
+	p, err := mr.NextPart() // The quoted-printable encoding is undone by the reader.
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Lines end with CRLF in the encoded text.
+	crlf := bytes.ReplaceAll(e.Text, []byte("\n"), []byte("\r\n"))
+	if text, _ := ioutil.ReadAll(p); p.Header.Get("Content-Type") != "text/plain; charset=utf-8" || !bytes.Equal(text, crlf) {
+		t.Errorf("bad text part %v: %q", p.Header, text)
+	}
+
+	p, err = mr.NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, params, _ = mime.ParseMediaType(p.Header.Get("Content-Type"))
+	if mt != "multipart/related" {
+		t.Fatalf("bad related part %v", p.Header)
+	}
+	rr := multipart.NewReader(p, params["boundary"])
+	if p, err = rr.NextPart(); err != nil {
+		t.Fatal(err)
+	}
+	if html, _ := ioutil.ReadAll(p); !bytes.Equal(html, e.HTML) {
+		t.Errorf("bad HTML part: %s", html)
+	}
+	for _, im := range e.Images {
+		p, err := rr.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := ioutil.ReadAll(p)
+		data, err := base64.StdEncoding.DecodeString(string(raw))
+		if p.Header.Get("Content-Id") != "<"+im.ContentID+">" || err != nil || !bytes.Equal(data, im.Data) {
+			t.Errorf("bad image part %v: %q", p.Header, raw)
+		}
+	}
+	if _, err := rr.NextPart(); err == nil {
+		t.Errorf("extra part in the related part")
+	}
+	if _, err := mr.NextPart(); err == nil {
+		t.Errorf("extra part")
+	}
+
+}
+
+func TestEmail_Multipart(t *testing.T) {
+
+	e, err := RenderEmail([]byte(`[{"insert":"Café `+strings.Repeat("long ", 30)+`\n"}]`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ct, body, err := e.Multipart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, params, _ := mime.ParseMediaType(ct)
+	mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+
+	for _, want := range []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"} {
+		p, err := mr.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.Header.Get("Content-Type"); got != want {
+			t.Errorf("got part %q; want %q", got, want)
+		}
+	}
+	if _, err := mr.NextPart(); err == nil {
+		t.Errorf("extra part")
+	}
+
+	// No line of the body is too long for mail servers.
+	for _, line := range strings.Split(string(body), "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("line too long: %q", line)
+		}
+	}
+
+}
@@ -3,7 +3,6 @@ package quill
 import (
 	"bytes"
 	"sort"
-	"strconv"
 )
 
 // A formatState holds the current state of open tag, class, or style formats.
@@ -94,10 +93,10 @@ func (fs *formatState) writeFormats(buf *bytes.Buffer) {
 			buf.WriteString(f.Val)
 		case Class:
 			buf.WriteString("span class=")
-			buf.WriteString(strconv.Quote(f.Val))
+			buf.WriteString(quoteAttr(f.Val))
 		case Style:
 			buf.WriteString("span style=")
-			buf.WriteString(strconv.Quote(f.Val))
+			buf.WriteString(quoteAttr(f.Val))
 		}
 
 		buf.WriteByte('>')
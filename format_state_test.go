@@ -61,7 +61,7 @@ func TestFormatState_add(t *testing.T) {
 
 		fs = ca.current
 
-		fmTer := ca.o.getFormatter(ca.keyword, nil)
+		fmTer := ca.o.getFormatter(ca.keyword, defaultRenderer)
 		fm := fmTer.Fmt()
 		fm.fm = fmTer
 
@@ -99,12 +99,12 @@ func TestFormatState_closePrevious(t *testing.T) {
 
 	cases := []formatState{
 		{
-			{"em", Tag, false, false, "", "", o1.getFormatter("italic", nil)},
-			{"strong", Tag, false, false, "", "", o1.getFormatter("bold", nil)},
+			{"em", Tag, false, false, "", "", o1.getFormatter("italic", defaultRenderer)},
+			{"strong", Tag, false, false, "", "", o1.getFormatter("bold", defaultRenderer)},
 		},
 		{
-			{"background-color:#e0e0e0;", Style, false, false, "", "", o2.getFormatter("background", nil)},
-			{"em", Tag, false, false, "", "", o2.getFormatter("italic", nil)},
+			{"background-color:#e0e0e0;", Style, false, false, "", "", o2.getFormatter("background", defaultRenderer)},
+			{"em", Tag, false, false, "", "", o2.getFormatter("italic", defaultRenderer)},
 		},
 	}
 
@@ -191,7 +191,7 @@ func TestFormatState_Sort(t *testing.T) {
 			fsCase = append(fsCase, &Format{
 				Val:   s.Val,
 				Place: s.Place,
-				fm:    o.getFormatter(s.keyword, nil),
+				fm:    o.getFormatter(s.keyword, defaultRenderer),
 			})
 		}
 
@@ -202,7 +202,7 @@ func TestFormatState_Sort(t *testing.T) {
 			fsWant = append(fsWant, &Format{
 				Val:   s.Val,
 				Place: s.Place,
-				fm:    o.getFormatter(s.keyword, nil),
+				fm:    o.getFormatter(s.keyword, defaultRenderer),
 			})
 		}
 
@@ -0,0 +1,47 @@
+package quill
+
+import "io"
+
+// formula
+type formulaFormat struct {
+	tex    string // the LaTeX source of the formula
+	mathML string // the MathML of the formula, or blank if the source is not in the supported subset of LaTeX
+	plain  bool   // write only the source, for email clients that show neither MathML nor the span
+}
+
+// newFormulaFormat returns the format for the formula embedded by o, converting the formula to MathML.
+func newFormulaFormat(o *Op) Formatter {
+	mathML, err := texToMathML(o.Data)
+	if err != nil {
+		mathML = ""
+	}
+	return &formulaFormat{
+		tex:    o.Data,
+		mathML: mathML,
+	}
+}
+
+func (*formulaFormat) Fmt() *Format { return nil } // The body contains the entire element.
+
+func (ff *formulaFormat) HasFormat(o *Op) bool {
+	return o.Type == "formula" && o.Data == ff.tex
+}
+
+// formulaFormat implements the FormatWriter interface. Like Quill, it writes a span with the ql-formula class that
+// keeps the source in its data-value attribute. The span holds the MathML of the formula or, if the formula could not
+// be converted, its source.
+func (ff *formulaFormat) Write(buf io.Writer) {
+	if ff.plain {
+		textEscaper.WriteString(buf, ff.tex)
+		return
+	}
+	io.WriteString(buf, `<span class="ql-formula" data-value=`)
+	io.WriteString(buf, quoteAttr(ff.tex))
+	io.WriteString(buf, ">")
+	if ff.mathML != "" {
+		io.WriteString(buf, ff.mathML)
+	} else {
+		textEscaper.WriteString(buf, ff.tex)
+	}
+	io.WriteString(buf, "</span>")
+}
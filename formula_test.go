@@ -0,0 +1,101 @@
+package quill
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTeXToMathML(t *testing.T) {
+
+	cases := map[string]string{
+		`e=mc^2`:                   `<mi>e</mi><mo>=</mo><mi>m</mi><msup><mi>c</mi><mn>2</mn></msup>`,
+		`\frac{a+b}{2}`:            `<mfrac><mrow><mi>a</mi><mo>+</mo><mi>b</mi></mrow><mn>2</mn></mfrac>`,
+		`\frac12`:                  `<mfrac><mn>1</mn><mn>2</mn></mfrac>`,
+		`x_{i-1}^2`:                `<msubsup><mi>x</mi><mrow><mi>i</mi><mo>−</mo><mn>1</mn></mrow><mn>2</mn></msubsup>`,
+		`f'(x)`:                    `<msup><mi>f</mi><mo>′</mo></msup><mo>(</mo><mi>x</mi><mo>)</mo>`,
+		`\alpha+\Omega`:            `<mi>α</mi><mo>+</mo><mi mathvariant="normal">Ω</mi>`,
+		`\sqrt{2}`:                 `<msqrt><mn>2</mn></msqrt>`,
+		`\sqrt[n]{x}`:              `<mroot><mi>x</mi><mi>n</mi></mroot>`,
+		`\sum_{i=1}^n i`:           `<munderover><mo>∑</mo><mrow><mi>i</mi><mo>=</mo><mn>1</mn></mrow><mi>n</mi></munderover><mi>i</mi>`,
+		`\int_0^1 x\,dx`:           `<msubsup><mo>∫</mo><mn>0</mn><mn>1</mn></msubsup><mi>x</mi><mspace width="0.1667em"></mspace><mi>d</mi><mi>x</mi>`,
+		`\lim_{x\to0}`:             `<munder><mi>lim</mi><mrow><mi>x</mi><mo>→</mo><mn>0</mn></mrow></munder>`,
+		`\sin x`:                   `<mrow><mi>sin</mi><mo>⁡</mo></mrow><mi>x</mi>`,
+		`a<b \leq c`:               `<mi>a</mi><mo>&lt;</mo><mi>b</mi><mo>≤</mo><mi>c</mi>`,
+		`\left[\frac{1}{x}\right.`: `<mrow><mo fence="true" stretchy="true">[</mo><mfrac><mn>1</mn><mi>x</mi></mfrac></mrow>`,
+		`\begin{pmatrix}1&0\\0&1\end{pmatrix}`: `<mrow><mo fence="true" stretchy="true">(</mo><mtable>` +
+			`<mtr><mtd><mn>1</mn></mtd><mtd><mn>0</mn></mtd></mtr><mtr><mtd><mn>0</mn></mtd><mtd><mn>1</mn></mtd></mtr>` +
+			`</mtable><mo fence="true" stretchy="true">)</mo></mrow>`,
+		`|x|=\begin{cases}x&x\ge0\\-x&\text{else}\end{cases}`: `<mo>|</mo><mi>x</mi><mo>|</mo><mo>=</mo><mrow>` +
+			`<mo fence="true" stretchy="true">{</mo><mtable columnalign="left left"><mtr><mtd><mi>x</mi></mtd>` +
+			`<mtd><mi>x</mi><mo>≥</mo><mn>0</mn></mtd></mtr><mtr><mtd><mo>−</mo><mi>x</mi></mtd><mtd><mtext>else</mtext></mtd>` +
+			`</mtr></mtable></mrow>`,
+		`\mathbb{R}^3`:    `<msup><mi mathvariant="double-struck">R</mi><mn>3</mn></msup>`,
+		`\vec{v}`:         `<mover accent="true"><mi>v</mi><mo>→</mo></mover>`,
+		`\text{ if } x`:   "<mtext>\u00a0if\u00a0</mtext><mi>x</mi>",
+		`\binom{n}{k}`:    `<mrow><mo>(</mo><mfrac linethickness="0"><mi>n</mi><mi>k</mi></mfrac><mo>)</mo></mrow>`,
+		`\bigl(x\bigr)`:   `<mo minsize="1.2em" maxsize="1.2em">(</mo><mi>x</mi><mo minsize="1.2em" maxsize="1.2em">)</mo>`,
+		`\sum\limits_i x`: `<munder><mo>∑</mo><mi>i</mi></munder><mi>x</mi>`,
+	}
+
+	for tex, want := range cases {
+		got, err := texToMathML(tex)
+		if err != nil {
+			t.Errorf("%s: %s", tex, err)
+			continue
+		}
+		want = `<math xmlns="http://www.w3.org/1998/Math/MathML"><semantics><mrow>` + want +
+			`</mrow><annotation encoding="application/x-tex">` + textEscaper.Replace(tex) + `</annotation></semantics></math>`
+		if got != want {
+			t.Errorf("%s: bad MathML\ngot:  %s\nwant: %s", tex, got, want)
+		}
+	}
+
+	for _, tex := range []string{
+		``, `\unknown`, `{a`, `a}`, `x^1^2`, `\frac{1}`, `\left(x`, `a\\b`, `\begin{array}x\end{array}`,
+		`\begin{matrix}1\end{pmatrix}`, `\mathbf{x+y}`, `\sqrt[3{x}`, `a & b`, strings.Repeat("{", 100) + strings.Repeat("}", 100),
+	} {
+		if got, err := texToMathML(tex); err == nil {
+			t.Errorf("%s: no error; got %s", tex, got)
+		}
+	}
+
+}
+
+func TestFormula(t *testing.T) {
+
+	cases := map[string]struct {
+		ops  string
+		want string
+	}{
+		"supported": {
+			ops: `[{"insert":"Einstein: "},{"insert":{"formula":"e=mc^2"}},{"insert":"\n"}]`,
+			want: `<p>Einstein: <span class="ql-formula" data-value="e=mc^2"><math xmlns="http://www.w3.org/1998/Math/MathML">` +
+				`<semantics><mrow><mi>e</mi><mo>=</mo><mi>m</mi><msup><mi>c</mi><mn>2</mn></msup></mrow>` +
+				`<annotation encoding="application/x-tex">e=mc^2</annotation></semantics></math></span></p>`,
+		},
+		"unsupported": {
+			ops:  `[{"insert":{"formula":"\\oddity{<b>}\""}},{"insert":"\n"}]`,
+			want: `<p><span class="ql-formula" data-value="\oddity{&lt;b&gt;}&#34;">\oddity{&lt;b&gt;}"</span></p>`,
+		},
+	}
+
+	for name, c := range cases {
+		got, err := Render([]byte(c.ops))
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if string(got) != c.want {
+			t.Errorf("%s: bad rendering\ngot:  %s\nwant: %s", name, got, c.want)
+		}
+		// The HTML of formulas can be imported back.
+		imported, err := ImportHTML(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, imported, []byte(c.ops)) {
+			t.Errorf("%s: bad import of %s; got: %s", name, got, imported)
+		}
+	}
+
+}
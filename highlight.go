@@ -0,0 +1,137 @@
+package quill
+
+import "strings"
+
+// A HighlightStyle selects the CSS classes that syntax highlighting puts on the tokens of code blocks.
+type HighlightStyle uint8
+
+const (
+	// NoHighlighting leaves code blocks as plain text. This is the default.
+	NoHighlighting HighlightStyle = iota
+
+	// HLJSClasses uses the classes of highlight.js, such as hljs-keyword and hljs-string, so that highlight.js themes
+	// can be used.
+	HLJSClasses
+
+	// PygmentsClasses uses the short classes of Pygments, such as k and s, so that Pygments (and Chroma) themes can be
+	// used.
+	PygmentsClasses
+)
+
+// WithSyntaxHighlighting makes the Renderer highlight the code in code blocks, wrapping each token in a span with a
+// class of the given style. The language is taken from the value of the "code-block" attribute (as Quill 2 sets it)
+// or, if there is none, detected from the code. Go, JavaScript, TypeScript, Python, SQL, JSON, shell, HTML and CSS
+// are known; code in other languages is left as plain text.
+//
+// Inline formats within highlighted code blocks are not written.
+func WithSyntaxHighlighting(style HighlightStyle) Option {
+	return func(r *Renderer) {
+		r.highlight = style
+	}
+}
+
+// The kinds of tokens that code is split into.
+const (
+	plainToken = iota
+	commentToken
+	stringToken
+	numberToken
+	keywordToken
+	literalToken
+	builtinToken
+	titleToken
+	attrToken
+	nameToken
+	variableToken
+	metaToken
+	selectorTagToken
+	selectorClassToken
+	selectorIDToken
+	propertyToken
+)
+
+// highlightClasses gives the class of each kind of token for each HighlightStyle.
+var highlightClasses = map[HighlightStyle][]string{
+	HLJSClasses: {
+		"", "hljs-comment", "hljs-string", "hljs-number", "hljs-keyword", "hljs-literal", "hljs-built_in",
+		"hljs-title", "hljs-attr", "hljs-name", "hljs-variable", "hljs-meta", "hljs-selector-tag",
+		"hljs-selector-class", "hljs-selector-id", "hljs-attribute",
+	},
+	PygmentsClasses: {
+		"", "c", "s", "m", "k", "kc", "nb", "nf", "na", "nt", "nv", "cp", "nt", "nc", "nn", "py",
+	},
+}
+
+// A codeToken is a piece of code of a single kind.
+type codeToken struct {
+	kind int
+	text string
+}
+
+// bufferCode holds the lines of a code block until the block is complete, since the whole block is needed to detect
+// its language and to find comments and strings that run over several lines.
+func (vars *renderVars) bufferCode(b *Block) {
+	vars.code = append(vars.code, b)
+}
+
+// flushCode writes out the buffered lines of a code block with the code highlighted.
+func (vars *renderVars) flushCode() error {
+
+	if len(vars.code) == 0 {
+		return nil
+	}
+	lines := vars.code
+	vars.code = vars.code[:0]
+
+	texts := make([]string, len(lines))
+	for i, b := range lines {
+		texts[i] = b.Text()
+	}
+	code := strings.Join(texts, "\n")
+
+	lang := codeLanguage(lines[0].Attrs["code-block"])
+	if lang == nil && lines[0].Attrs["code-block"] == "y" {
+		lang = detectLanguage(code)
+	}
+	var tokens []codeToken
+	if lang != nil {
+		tokens = lang.tokenize(code)
+	} else {
+		tokens = []codeToken{{plainToken, code}}
+	}
+
+	classes := highlightClasses[vars.r.highlight]
+	for _, b := range lines {
+		if err := vars.openBlock(b); err != nil {
+			return err
+		}
+		// Write out the tokens up to the end of the line, splitting a token that runs over it.
+		for len(tokens) > 0 {
+			t := &tokens[0]
+			text := t.text
+			i := strings.IndexByte(text, '\n')
+			if i != -1 {
+				text = text[:i]
+			}
+			if text != "" {
+				if class := classes[t.kind]; class != "" {
+					vars.tempBuf.WriteString(`<span class="` + class + `">`)
+					vars.writeText(&vars.tempBuf, text)
+					vars.tempBuf.WriteString("</span>")
+				} else {
+					vars.writeText(&vars.tempBuf, text)
+				}
+			}
+			if i == -1 {
+				tokens = tokens[1:]
+				continue
+			}
+			t.text = t.text[i+1:]
+			break
+		}
+		vars.closeBlock()
+	}
+
+	return nil
+
+}
@@ -0,0 +1,702 @@
+package quill
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// A codeLang describes how to split the code of a language into tokens. Most languages are handled by the generic
+// lexer with the settings here; those that are not set lex.
+type codeLang struct {
+	lineComments   []string
+	blockComments  [][2]string
+	strings        []stringDelim
+	stringPrefixes map[string]bool // identifiers that can start a string literal, such as r or f in Python
+	keywords       map[string]bool
+	literals       map[string]bool
+	builtins       map[string]bool
+	titleAfter     map[string]bool // keywords after which an identifier is the name of a function or type
+	foldCase       bool            // whether keywords are not case-sensitive
+	identChars     string          // characters other than letters, digits and "_" that can be in an identifier
+	variables      bool            // whether $name and ${name} are variables
+	keyStrings     bool            // whether a string followed by a ":" is a key
+	decorators     bool            // whether @name is a decorator
+	spacedComments bool            // whether line comments must start a word
+	lex            func(code string) []codeToken
+}
+
+// A stringDelim gives how a kind of string literal starts and ends.
+type stringDelim struct {
+	open, close string
+	escapes     bool // whether a backslash escapes the next character
+	multiline   bool // whether the string can run over several lines
+	doubled     bool // whether a doubled close delimiter stands for itself
+}
+
+// codeLanguages holds the known languages by name and alias.
+var codeLanguages = map[string]*codeLang{}
+
+func init() {
+	for names, lang := range map[string]*codeLang{
+		"go golang":                                  goLang,
+		"javascript js jsx mjs node":                 jsLang,
+		"typescript ts tsx":                          tsLang,
+		"python py python3":                          pythonLang,
+		"sql mysql postgresql postgres sqlite plsql": sqlLang,
+		"json jsonc":                                 jsonLang,
+		"bash sh shell zsh console shellsession":     shellLang,
+		"html xml xhtml svg htm":                     htmlLang,
+		"css":                                        cssLang,
+	} {
+		for _, name := range strings.Fields(names) {
+			codeLanguages[name] = lang
+		}
+	}
+}
+
+// codeLanguage returns the language with the given name, or nil if it is not known.
+func codeLanguage(name string) *codeLang {
+	return codeLanguages[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// wordSet makes a set of the space-separated words.
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+var goLang = &codeLang{
+	lineComments:  []string{"//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	strings: []stringDelim{
+		{open: `"`, close: `"`, escapes: true},
+		{open: "'", close: "'", escapes: true},
+		{open: "`", close: "`", multiline: true},
+	},
+	keywords: wordSet(`break case chan const continue default defer else fallthrough for func go goto if import
+		interface map package range return select struct switch type var`),
+	literals: wordSet("true false nil iota"),
+	builtins: wordSet(`append cap clear close complex copy delete imag len make max min new panic print println real
+		recover any bool byte comparable complex64 complex128 error float32 float64 int int8 int16 int32 int64 rune
+		string uint uint8 uint16 uint32 uint64 uintptr`),
+	titleAfter: wordSet("func type"),
+}
+
+const jsKeywords = `async await break case catch class const continue debugger default delete do else export extends
+	finally for from function get if import in instanceof let new of return set static super switch this throw try
+	typeof var void while with yield`
+
+var jsLang = &codeLang{
+	lineComments:  []string{"//"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	strings: []stringDelim{
+		{open: `"`, close: `"`, escapes: true},
+		{open: "'", close: "'", escapes: true},
+		{open: "`", close: "`", escapes: true, multiline: true},
+	},
+	keywords: wordSet(jsKeywords),
+	literals: wordSet("true false null undefined NaN Infinity"),
+	builtins: wordSet(`Array Boolean Date Error JSON Map Math Number Object Promise RegExp Set String Symbol console
+		document exports module parseFloat parseInt require window`),
+	titleAfter: wordSet("function class"),
+	identChars: "$",
+}
+
+var tsLang = &codeLang{
+	lineComments:  jsLang.lineComments,
+	blockComments: jsLang.blockComments,
+	strings:       jsLang.strings,
+	keywords: wordSet(jsKeywords + ` abstract as declare enum implements infer interface is keyof namespace private
+		protected public readonly type`),
+	literals:   jsLang.literals,
+	builtins:   wordSet("any bigint boolean never number object string symbol unknown " + strings.Join(setWords(jsLang.builtins), " ")),
+	titleAfter: wordSet("function class interface type enum"),
+	identChars: "$",
+}
+
+var pythonLang = &codeLang{
+	lineComments: []string{"#"},
+	strings: []stringDelim{
+		{open: `"""`, close: `"""`, escapes: true, multiline: true},
+		{open: "'''", close: "'''", escapes: true, multiline: true},
+		{open: `"`, close: `"`, escapes: true},
+		{open: "'", close: "'", escapes: true},
+	},
+	stringPrefixes: wordSet("r u b f rb br fr rf R U B F RB BR FR RF Rb rB Br bR Fr fR Rf rF"),
+	keywords: wordSet(`and as assert async await break case class continue def del elif else except finally for from
+		global if import in is lambda match nonlocal not or pass raise return try while with yield`),
+	literals: wordSet("True False None"),
+	builtins: wordSet(`abs all any bool bytes callable chr cls dict dir enumerate Exception filter float format
+		getattr hasattr hash int isinstance issubclass iter len list map max min next object open ord print range
+		repr reversed round self set setattr sorted str sum super tuple type zip`),
+	titleAfter: wordSet("def class"),
+	decorators: true,
+}
+
+var sqlLang = &codeLang{
+	lineComments:  []string{"--"},
+	blockComments: [][2]string{{"/*", "*/"}},
+	strings: []stringDelim{
+		{open: "'", close: "'", multiline: true, doubled: true},
+		{open: `"`, close: `"`, doubled: true},
+	},
+	keywords: wordSet(`add all alter and as asc begin between by cascade case check commit constraint create cross
+		default delete desc distinct drop else end except exists foreign from full group having if in index inner
+		insert intersect into is join key left like limit not offset on or order outer primary references returning
+		right rollback select set table then transaction union unique update using values view when where with`),
+	literals: wordSet("true false null"),
+	builtins: wordSet(`avg bigint boolean cast char coalesce count date decimal float int integer length lower max
+		min now numeric real serial smallint substring sum text timestamp upper varchar`),
+	foldCase: true,
+}
+
+var jsonLang = &codeLang{
+	strings:    []stringDelim{{open: `"`, close: `"`, escapes: true}},
+	literals:   wordSet("true false null"),
+	keyStrings: true,
+}
+
+var shellLang = &codeLang{
+	lineComments: []string{"#"},
+	strings: []stringDelim{
+		{open: `"`, close: `"`, escapes: true, multiline: true},
+		{open: "'", close: "'", multiline: true},
+	},
+	keywords: wordSet(`case declare do done elif else esac export fi for function if in local readonly return select
+		then until unset while`),
+	literals: wordSet("true false"),
+	builtins: wordSet(`alias awk cat cd chmod chown cp curl echo eval exec exit git grep kill ls make mkdir mv printf
+		pwd read rm sed set shift source sudo tar test trap wget`),
+	identChars:     "-",
+	variables:      true,
+	spacedComments: true,
+}
+
+var htmlLang = &codeLang{lex: lexHTML}
+
+var cssLang = &codeLang{lex: lexCSS}
+
+// setWords returns the words of a set.
+func setWords(set map[string]bool) []string {
+	words := make([]string, 0, len(set))
+	for w := range set {
+		words = append(words, w)
+	}
+	return words
+}
+
+// A codeLexer splits code into tokens.
+type codeLexer struct {
+	code   string
+	pos    int
+	tokens []codeToken
+}
+
+// emit adds a token, joining it to the last one if both are of the same kind.
+func (lx *codeLexer) emit(kind int, text string) {
+	if text == "" {
+		return
+	}
+	if n := len(lx.tokens); n > 0 && lx.tokens[n-1].kind == kind {
+		lx.tokens[n-1].text += text
+		return
+	}
+	lx.tokens = append(lx.tokens, codeToken{kind, text})
+}
+
+// take emits the next n bytes as a token.
+func (lx *codeLexer) take(kind, n int) {
+	if lx.pos+n > len(lx.code) {
+		n = len(lx.code) - lx.pos
+	}
+	lx.emit(kind, lx.code[lx.pos:lx.pos+n])
+	lx.pos += n
+}
+
+// rest returns the code that is left.
+func (lx *codeLexer) rest() string {
+	return lx.code[lx.pos:]
+}
+
+// untilAfter returns the length of the rest of the code up to and including end, or all of it if end is not found.
+func (lx *codeLexer) untilAfter(from int, end string) int {
+	i := strings.Index(lx.code[lx.pos+from:], end)
+	if i == -1 {
+		return len(lx.code) - lx.pos
+	}
+	return from + i + len(end)
+}
+
+// tokenize splits code into tokens.
+func (cl *codeLang) tokenize(code string) []codeToken {
+
+	if cl.lex != nil {
+		return cl.lex(code)
+	}
+
+	lx := codeLexer{code: code}
+	prevWord := "" // the last keyword or identifier
+
+	for lx.pos < len(code) {
+
+		rest := lx.rest()
+		c := rest[0]
+
+		if d, ok := cl.stringAt(rest); ok {
+			n := scanString(rest, d)
+			kind := stringToken
+			if cl.keyStrings && strings.HasPrefix(strings.TrimLeft(rest[n:], " \t"), ":") {
+				kind = attrToken
+			}
+			lx.take(kind, n)
+			prevWord = ""
+			continue
+		}
+
+		if n := cl.commentAt(&lx, rest); n > 0 {
+			lx.take(commentToken, n)
+			continue
+		}
+
+		switch {
+		case cl.variables && c == '$' && len(rest) > 1:
+			n := 1
+			if rest[1] == '{' {
+				n = lx.untilAfter(1, "}")
+			} else {
+				for n < len(rest) && (isWordByte(rest[n]) || n == 1 && strings.IndexByte("@*#?$!0123456789", rest[n]) != -1) {
+					n++
+					if n == 2 && !isWordByte(rest[1]) {
+						break
+					}
+				}
+			}
+			if n == 1 {
+				lx.take(plainToken, 1)
+				continue
+			}
+			lx.take(variableToken, n)
+		case cl.decorators && c == '@' && len(rest) > 1 && isIdentStart(rest[1]):
+			n := 1
+			for n < len(rest) && (isWordByte(rest[n]) || rest[n] == '.') {
+				n++
+			}
+			lx.take(metaToken, n)
+		case isDigit(c) || c == '.' && len(rest) > 1 && isDigit(rest[1]):
+			if lx.pos > 0 && (isWordByte(code[lx.pos-1]) || strings.IndexByte(cl.identChars, code[lx.pos-1]) != -1) {
+				lx.take(plainToken, 1)
+				continue
+			}
+			lx.take(numberToken, scanNumber(rest))
+		case isIdentStart(c) || strings.IndexByte(cl.identChars, c) != -1 && c != '-':
+			n := 1
+			for n < len(rest) && (isWordByte(rest[n]) || strings.IndexByte(cl.identChars, rest[n]) != -1) {
+				n++
+			}
+			word := rest[:n]
+			if cl.stringPrefixes[word] && n < len(rest) {
+				if d, ok := cl.stringAt(rest[n:]); ok {
+					lx.take(stringToken, n+scanString(rest[n:], d))
+					continue
+				}
+			}
+			key := word
+			if cl.foldCase {
+				key = strings.ToLower(word)
+			}
+			kind := plainToken
+			switch {
+			case cl.keywords[key]:
+				kind = keywordToken
+			case cl.literals[key]:
+				kind = literalToken
+			case cl.titleAfter[prevWord]:
+				kind = titleToken
+			case cl.builtins[key]:
+				kind = builtinToken
+			}
+			lx.take(kind, n)
+			prevWord = key
+		default:
+			if !isSpace(c) {
+				prevWord = ""
+			}
+			lx.take(plainToken, 1)
+		}
+
+	}
+
+	return lx.tokens
+
+}
+
+// stringAt returns the kind of string that starts at the start of s.
+func (cl *codeLang) stringAt(s string) (stringDelim, bool) {
+	for _, d := range cl.strings {
+		if strings.HasPrefix(s, d.open) {
+			return d, true
+		}
+	}
+	return stringDelim{}, false
+}
+
+// commentAt returns the length of the comment that starts at the start of s, or 0 if none does.
+func (cl *codeLang) commentAt(lx *codeLexer, s string) int {
+	for _, lc := range cl.lineComments {
+		if !strings.HasPrefix(s, lc) {
+			continue
+		}
+		if cl.spacedComments && lx.pos > 0 && !isSpace(lx.code[lx.pos-1]) {
+			continue
+		}
+		if i := strings.IndexByte(s, '\n'); i != -1 {
+			return i
+		}
+		return len(s)
+	}
+	for _, bc := range cl.blockComments {
+		if strings.HasPrefix(s, bc[0]) {
+			return lx.untilAfter(len(bc[0]), bc[1])
+		}
+	}
+	return 0
+}
+
+// scanString returns the length of the string literal of the kind d at the start of s. A string that is not closed
+// runs to the end of the line, or to the end of the code if it can run over several lines.
+func scanString(s string, d stringDelim) int {
+	i := len(d.open)
+	for i < len(s) {
+		switch {
+		case d.escapes && s[i] == '\\':
+			i += 2
+			continue
+		case s[i] == '\n' && !d.multiline:
+			return i
+		case strings.HasPrefix(s[i:], d.close):
+			if d.doubled && strings.HasPrefix(s[i+len(d.close):], d.close) {
+				i += 2 * len(d.close)
+				continue
+			}
+			return i + len(d.close)
+		}
+		i++
+	}
+	return len(s)
+}
+
+// scanNumber returns the length of the number at the start of s.
+func scanNumber(s string) int {
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case isWordByte(c), c == '.' && i+1 < len(s) && isDigit(s[i+1]):
+		case (c == '+' || c == '-') && i > 0 && (s[i-1] == 'e' || s[i-1] == 'E') && !strings.HasPrefix(s, "0x"):
+		default:
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return isLetter(c) || c == '_' || c >= 0x80
+}
+
+func isWordByte(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+// lexHTML splits HTML or XML into tokens. The content of script and style elements is split as JavaScript and CSS.
+func lexHTML(code string) []codeToken {
+
+	lx := codeLexer{code: code}
+
+	for lx.pos < len(code) {
+		rest := lx.rest()
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			lx.take(commentToken, lx.untilAfter(4, "-->"))
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			lx.take(metaToken, lx.untilAfter(2, ">"))
+		case rest[0] == '<' && len(rest) > 1 && (isLetter(rest[1]) || rest[1] == '/'):
+			name := lexHTMLTag(&lx)
+			if name != "script" && name != "style" {
+				continue
+			}
+			end := indexFold(lx.rest(), "</"+name)
+			if end == -1 {
+				end = len(lx.rest())
+			}
+			inner := codeLanguage("javascript")
+			if name == "style" {
+				inner = cssLang
+			}
+			for _, t := range inner.tokenize(lx.rest()[:end]) {
+				lx.emit(t.kind, t.text)
+			}
+			lx.pos += end
+		default:
+			n := strings.IndexByte(rest[1:], '<')
+			if n == -1 {
+				n = len(rest) - 1
+			}
+			lx.take(plainToken, n+1)
+		}
+	}
+
+	return lx.tokens
+
+}
+
+// lexHTMLTag splits a start or end tag into tokens, returning the lower-case name of a start tag.
+func lexHTMLTag(lx *codeLexer) string {
+
+	closing := strings.HasPrefix(lx.rest(), "</")
+	if closing {
+		lx.take(plainToken, 2)
+	} else {
+		lx.take(plainToken, 1)
+	}
+
+	n := 0
+	rest := lx.rest()
+	for n < len(rest) && !isSpace(rest[n]) && rest[n] != '>' && rest[n] != '/' {
+		n++
+	}
+	name := strings.ToLower(rest[:n])
+	lx.take(nameToken, n)
+
+	for lx.pos < len(lx.code) {
+		rest = lx.rest()
+		c := rest[0]
+		switch {
+		case c == '>':
+			lx.take(plainToken, 1)
+			if closing {
+				return ""
+			}
+			return name
+		case c == '"' || c == '\'':
+			lx.take(stringToken, scanString(rest, stringDelim{open: string(c), close: string(c), multiline: true}))
+		case c == '=':
+			lx.take(plainToken, 1)
+			if rest = lx.rest(); rest != "" && rest[0] != '"' && rest[0] != '\'' {
+				n = 0
+				for n < len(rest) && !isSpace(rest[n]) && rest[n] != '>' {
+					n++
+				}
+				lx.take(stringToken, n)
+			}
+		case isSpace(c) || c == '/':
+			lx.take(plainToken, 1)
+		default:
+			n = 0
+			for n < len(rest) && !isSpace(rest[n]) && strings.IndexByte("=>/\"'", rest[n]) == -1 {
+				n++
+			}
+			lx.take(attrToken, n)
+		}
+	}
+
+	return ""
+
+}
+
+// lexCSS splits CSS into tokens: selectors outside of declaration blocks, and properties and values inside of them.
+func lexCSS(code string) []codeToken {
+
+	lx := codeLexer{code: code}
+	var blocks []bool // for each open block, whether it holds declarations (rather than rules, as in @media)
+	atRule := false   // whether the current prelude is that of an at-rule
+
+	for lx.pos < len(code) {
+
+		rest := lx.rest()
+		c := rest[0]
+		inDecls := len(blocks) > 0 && blocks[len(blocks)-1]
+
+		switch {
+		case strings.HasPrefix(rest, "/*"):
+			lx.take(commentToken, lx.untilAfter(2, "*/"))
+		case c == '"' || c == '\'':
+			lx.take(stringToken, scanString(rest, stringDelim{open: string(c), close: string(c), escapes: true}))
+		case c == '@':
+			n := 1
+			for n < len(rest) && (isWordByte(rest[n]) || rest[n] == '-') {
+				n++
+			}
+			lx.take(keywordToken, n)
+			atRule = true
+		case c == '{':
+			blocks = append(blocks, !atRule || cssDeclAtRule(lx.tokens))
+			atRule = false
+			lx.take(plainToken, 1)
+		case c == '}':
+			if len(blocks) > 0 {
+				blocks = blocks[:len(blocks)-1]
+			}
+			lx.take(plainToken, 1)
+		case c == ';':
+			atRule = false
+			lx.take(plainToken, 1)
+		case inDecls:
+			lexCSSDecl(&lx)
+		case atRule:
+			lexCSSValue(&lx)
+		default:
+			n := 1
+			for n < len(rest) && (isWordByte(rest[n]) || rest[n] == '-') {
+				n++
+			}
+			switch {
+			case c == '.':
+				lx.take(selectorClassToken, n)
+			case c == '#':
+				lx.take(selectorIDToken, n)
+			case c == ':':
+				for n < len(rest) && rest[n] == ':' {
+					n++
+					for n < len(rest) && (isWordByte(rest[n]) || rest[n] == '-') {
+						n++
+					}
+				}
+				lx.take(selectorClassToken, n)
+			case isIdentStart(c) || c == '*':
+				lx.take(selectorTagToken, n)
+			default:
+				lx.take(plainToken, 1)
+			}
+		}
+
+	}
+
+	return lx.tokens
+
+}
+
+// cssDeclAtRule says if the block of the at-rule that was just read holds declarations, as that of @font-face does.
+func cssDeclAtRule(tokens []codeToken) bool {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i].kind == keywordToken && strings.HasPrefix(tokens[i].text, "@") {
+			switch strings.ToLower(tokens[i].text) {
+			case "@font-face", "@page", "@property", "@counter-style":
+				return true
+			}
+			return false
+		}
+	}
+	return false
+}
+
+// lexCSSDecl reads the next token inside of a declaration block.
+func lexCSSDecl(lx *codeLexer) {
+	rest := lx.rest()
+	c := rest[0]
+	if isIdentStart(c) || c == '-' {
+		n := 1
+		for n < len(rest) && (isWordByte(rest[n]) || rest[n] == '-') {
+			n++
+		}
+		if strings.HasPrefix(strings.TrimLeft(rest[n:], " \t"), ":") {
+			lx.take(propertyToken, n)
+			return
+		}
+	}
+	lexCSSValue(lx)
+}
+
+// lexCSSValue reads the next token of a value.
+func lexCSSValue(lx *codeLexer) {
+	rest := lx.rest()
+	c := rest[0]
+	switch {
+	case isDigit(c) || (c == '.' || c == '-') && len(rest) > 1 && isDigit(rest[1]):
+		n := 1
+		for n < len(rest) && (isWordByte(rest[n]) || rest[n] == '.' || rest[n] == '%') {
+			n++
+		}
+		lx.take(numberToken, n)
+	case c == '#' && len(rest) > 1 && isWordByte(rest[1]):
+		n := 1
+		for n < len(rest) && isWordByte(rest[n]) {
+			n++
+		}
+		lx.take(numberToken, n)
+	case c == '!':
+		n := 1
+		for n < len(rest) && isLetter(rest[n]) {
+			n++
+		}
+		lx.take(keywordToken, n)
+	case isIdentStart(c) || c == '-':
+		n := 1
+		for n < len(rest) && (isWordByte(rest[n]) || rest[n] == '-') {
+			n++
+		}
+		if n < len(rest) && rest[n] == '(' {
+			lx.take(builtinToken, n)
+			return
+		}
+		lx.take(plainToken, n)
+	default:
+		lx.take(plainToken, 1)
+	}
+}
+
+// languageHints are patterns that suggest code is in a language, used to detect the language of code blocks that do
+// not give it.
+var languageHints = []struct {
+	lang    *codeLang
+	pattern *regexp.Regexp
+}{
+	{goLang, regexp.MustCompile(`(?m)^package \w+|^func |:= |\bfmt\.|\bfunc\(|^import \(|\bchan\b|\bdefer\b`)},
+	{jsLang, regexp.MustCompile(`\bfunction\b|\bconst \w+ =|\blet \w+|=> |\bconsole\.|\brequire\(|\bdocument\.|\bexport (default|const|function)`)},
+	{tsLang, regexp.MustCompile(`\binterface \w+ \{|: (string|number|boolean|any)\b|\bexport type\b|\bimplements\b|<\w+>\(`)},
+	{pythonLang, regexp.MustCompile(`(?m)^\s*def \w+\(.*\):|^\s*class \w+.*:$|^\s*(from \w+ )?import \w+$|\bself\.|\belif\b|\bprint\(|:\s*$`)},
+	{sqlLang, regexp.MustCompile(`(?i)\bselect\b.+\bfrom\b|\binsert into\b|\bcreate table\b|\bupdate \w+ set\b|\bdelete from\b|\bwhere\b`)},
+	{shellLang, regexp.MustCompile(`(?m)^#!/|^\$ |\becho\b|\bsudo\b|\bexport \w+=|\bfi$|\bdone$|\$\{?\w+\}?|\| grep\b`)},
+	{htmlLang, regexp.MustCompile(`</?[a-zA-Z][\w-]*(\s[^>]*)?>|<!DOCTYPE`)},
+	{cssLang, regexp.MustCompile(`(?m)^\s*[.#]?[\w-]+(\s*[,>+~]?\s*[.#]?[\w-]+)*\s*\{|^\s*[\w-]+\s*:\s*[^;]+;\s*$|@media\b`)},
+}
+
+// detectLanguage guesses the language of code from the number of lines in which the patterns of each language are
+// found. It returns nil if none are found.
+func detectLanguage(code string) *codeLang {
+
+	trimmed := strings.TrimSpace(code)
+	if trimmed == "" {
+		return nil
+	}
+	if (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid([]byte(trimmed)) {
+		return jsonLang
+	}
+
+	var best *codeLang
+	bestScore := 0
+	lines := strings.Split(code, "\n")
+	for _, h := range languageHints {
+		score := 0
+		for _, line := range lines {
+			if h.pattern.MatchString(line) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = h.lang, score
+		}
+	}
+
+	return best
+
+}
@@ -0,0 +1,145 @@
+package quill
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithSyntaxHighlighting(t *testing.T) {
+
+	cases := map[string]struct {
+		ops  string
+		want string
+	}{
+		"go": {
+			ops: `[{"insert":"func main() {"},{"attributes":{"code-block":"go"},"insert":"\n"},` +
+				`{"insert":"\tx := \"a<b\" // c"},{"attributes":{"code-block":"go"},"insert":"\n"},{"insert":"}"},{"attributes":{"code-block":"go"},"insert":"\n"}]`,
+			want: `<pre><span class="hljs-keyword">func</span> <span class="hljs-title">main</span>() {` + "\n" +
+				"\tx := " + `<span class="hljs-string">"a&lt;b"</span> <span class="hljs-comment">// c</span>` + "\n}\n</pre>",
+		},
+		"comment over several lines": {
+			ops:  `[{"insert":"/* a"},{"attributes":{"code-block":"js"},"insert":"\n"},{"insert":"b */ null"},{"attributes":{"code-block":"js"},"insert":"\n"}]`,
+			want: `<pre><span class="hljs-comment">/* a</span>` + "\n" + `<span class="hljs-comment">b */</span> <span class="hljs-literal">null</span>` + "\n</pre>",
+		},
+		"sql": {
+			ops:  `[{"insert":"Select 'it''s' from t limit 5;"},{"attributes":{"code-block":"sql"},"insert":"\n"}]`,
+			want: `<pre><span class="hljs-keyword">Select</span> <span class="hljs-string">'it''s'</span> <span class="hljs-keyword">from</span> t <span class="hljs-keyword">limit</span> <span class="hljs-number">5</span>;` + "\n</pre>",
+		},
+		"python": {
+			ops:  `[{"insert":"@cache"},{"attributes":{"code-block":"python"},"insert":"\n"},{"insert":"def f(): return r'\\d' # x"},{"attributes":{"code-block":"python"},"insert":"\n"}]`,
+			want: `<pre><span class="hljs-meta">@cache</span>` + "\n" + `<span class="hljs-keyword">def</span> <span class="hljs-title">f</span>(): <span class="hljs-keyword">return</span> <span class="hljs-string">r'\d'</span> <span class="hljs-comment"># x</span>` + "\n</pre>",
+		},
+		"shell": {
+			ops:  `[{"insert":"echo $HOME a#b # c"},{"attributes":{"code-block":"bash"},"insert":"\n"}]`,
+			want: `<pre><span class="hljs-built_in">echo</span> <span class="hljs-variable">$HOME</span> a#b <span class="hljs-comment"># c</span>` + "\n</pre>",
+		},
+		"html": {
+			ops:  `[{"insert":"<a href=\"x\">y</a><!-- z -->"},{"attributes":{"code-block":"html"},"insert":"\n"}]`,
+			want: `<pre>&lt;<span class="hljs-name">a</span> <span class="hljs-attr">href</span>=<span class="hljs-string">"x"</span>&gt;y&lt;/<span class="hljs-name">a</span>&gt;<span class="hljs-comment">&lt;!-- z --&gt;</span>` + "\n</pre>",
+		},
+		"css": {
+			ops:  `[{"insert":"p.a { color: #fff; }"},{"attributes":{"code-block":"css"},"insert":"\n"}]`,
+			want: `<pre><span class="hljs-selector-tag">p</span><span class="hljs-selector-class">.a</span> { <span class="hljs-attribute">color</span>: <span class="hljs-number">#fff</span>; }` + "\n</pre>",
+		},
+		"json detected": {
+			ops:  `[{"insert":"{\"a\": [1, true]}"},{"attributes":{"code-block":true},"insert":"\n"}]`,
+			want: `<pre>{<span class="hljs-attr">"a"</span>: [<span class="hljs-number">1</span>, <span class="hljs-literal">true</span>]}` + "\n</pre>",
+		},
+		"two languages": {
+			ops:  `[{"insert":"null"},{"attributes":{"code-block":"js"},"insert":"\n"},{"insert":"select 1"},{"attributes":{"code-block":"sql"},"insert":"\n"}]`,
+			want: `<pre><span class="hljs-literal">null</span>` + "\n</pre>" + `<pre><span class="hljs-keyword">select</span> <span class="hljs-number">1</span>` + "\n</pre>",
+		},
+		"unknown language": {
+			ops:  `[{"insert":"if x then y"},{"attributes":{"code-block":"plain"},"insert":"\n"},{"insert":"text\n"}]`,
+			want: "<pre>if x then y\n</pre><p>text</p>",
+		},
+	}
+
+	r := NewRenderer(WithSyntaxHighlighting(HLJSClasses))
+	for name, c := range cases {
+		got, err := r.Render([]byte(c.ops))
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if string(got) != c.want {
+			t.Errorf("%s: bad rendering\ngot:  %s\nwant: %s", name, got, c.want)
+		}
+	}
+
+}
+
+func TestWithSyntaxHighlighting_styles(t *testing.T) {
+
+	ops := `[{"insert":"return 1"},{"attributes":{"code-block":"javascript"},"insert":"\n"}]`
+
+	got, err := NewRenderer(WithSyntaxHighlighting(PygmentsClasses)).Render([]byte(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<pre><span class="k">return</span> <span class="m">1</span>` + "\n</pre>"
+	if string(got) != want {
+		t.Errorf("bad Pygments rendering\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// Without highlighting, the language is ignored.
+	got, err = NewRenderer().Render([]byte(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want = "<pre>return 1\n</pre>"; string(got) != want {
+		t.Errorf("bad rendering without highlighting\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// Quill 2 writes the language on the pre element.
+	got, err = NewRenderer(WithSyntaxHighlighting(HLJSClasses), WithQuillVersion(Quill2)).Render([]byte(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(got), `<pre data-language="javascript">`+"\n") {
+		t.Errorf("bad Quill 2 rendering: %s", got)
+	}
+
+}
+
+func TestDetectLanguage(t *testing.T) {
+
+	cases := map[string]*codeLang{
+		"package main\n\nfunc main() {\n\tfmt.Println(1)\n}":  goLang,
+		"const x = require('x');\nconsole.log(x);":            jsLang,
+		"interface A {\n  b: string;\n}":                      tsLang,
+		"def f(x):\n    return x\n\nprint(f(1))":              pythonLang,
+		"SELECT a, b FROM t\nWHERE a > 1;":                    sqlLang,
+		`[{"a": 1}, null]`:                                    jsonLang,
+		"#!/bin/sh\necho $HOME | grep root":                   shellLang,
+		"<!DOCTYPE html>\n<html><body><p>x</p></body></html>": htmlLang,
+		".a {\n  color: red;\n  margin: 0;\n}":                cssLang,
+		"just some words":                                     nil,
+	}
+
+	for code, want := range cases {
+		if got := detectLanguage(code); got != want {
+			t.Errorf("bad language detected for %q", code)
+		}
+	}
+
+}
+
+func TestRenderTo_highlighting(t *testing.T) {
+
+	ops := `[{"insert":"a"},{"attributes":{"code-block":"go"},"insert":"\n"},{"insert":"nil"},{"attributes":{"code-block":"go"},"insert":"\n"}]`
+	r := NewRenderer(WithSyntaxHighlighting(HLJSClasses))
+
+	want, err := r.Render([]byte(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	if err = r.RenderTo(&sb, strings.NewReader(ops)); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != string(want) {
+		t.Errorf("RenderTo and Render differ\nRenderTo: %s\nRender:   %s", sb.String(), want)
+	}
+
+}
@@ -0,0 +1,116 @@
+package quill
+
+import "time"
+
+// A History records the changes made to a document so that they can be undone and redone, in the way of Quill's
+// history module. Changes that are recorded in quick succession are grouped together and undone as one.
+//
+// A History does not hold the document itself: Undo and Redo take the current document and return the change to
+// apply to it.
+type History struct {
+	Delay    time.Duration // how long after the first change of a group other changes are added to it
+	MaxStack int           // how many groups can be undone, or no limit if 0
+
+	undo, redo   []*Delta
+	lastRecorded time.Time
+}
+
+// NewHistory returns a History with the settings that Quill uses by default: a Delay of one second and a MaxStack
+// of 100.
+func NewHistory() *History {
+	return &History{Delay: time.Second, MaxStack: 100}
+}
+
+// Record records a change that was made at the given time to the document base. Recording a change clears the changes
+// that could be redone.
+func (h *History) Record(change, base *Delta, at time.Time) {
+
+	if len(change.Ops) == 0 {
+		return
+	}
+	h.redo = nil
+
+	undo := change.Invert(base)
+	if n := len(h.undo); n > 0 && h.lastRecorded.Add(h.Delay).After(at) {
+		undo = undo.Compose(h.undo[n-1])
+		h.undo = h.undo[:n-1]
+	} else {
+		h.lastRecorded = at
+	}
+	if undo.Length() == 0 {
+		return
+	}
+
+	h.undo = append(h.undo, undo)
+	if h.MaxStack > 0 && len(h.undo) > h.MaxStack {
+		h.undo = h.undo[len(h.undo)-h.MaxStack:]
+	}
+
+}
+
+// Cutoff ends the current group, so that the next change recorded starts a new one.
+func (h *History) Cutoff() {
+	h.lastRecorded = time.Time{}
+}
+
+// Undo returns the change that undoes the last group of changes made to doc, which is the current document, and makes
+// it possible to redo them. It returns false if there is nothing to undo.
+func (h *History) Undo(doc *Delta) (*Delta, bool) {
+	return h.change(&h.undo, &h.redo, doc)
+}
+
+// Redo returns the change that redoes the last group of changes that was undone on doc, which is the current document.
+// It returns false if there is nothing to redo.
+func (h *History) Redo(doc *Delta) (*Delta, bool) {
+	return h.change(&h.redo, &h.undo, doc)
+}
+
+// change takes the last change off of the source stack and puts its inverse on the dest stack.
+func (h *History) change(source, dest *[]*Delta, doc *Delta) (*Delta, bool) {
+	n := len(*source)
+	if n == 0 {
+		return nil, false
+	}
+	d := (*source)[n-1]
+	*source = (*source)[:n-1]
+	*dest = append(*dest, d.Invert(doc))
+	h.Cutoff()
+	return d, true
+}
+
+// Transform changes the recorded changes to take in a change made to the document by someone else, so that undoing
+// does not undo the changes of others. Changes that are made void by it are dropped.
+func (h *History) Transform(change *Delta) {
+	h.undo = transformStack(h.undo, change)
+	h.redo = transformStack(h.redo, change)
+}
+
+// transformStack transforms the changes on the stack, from the last one down, over a change made after them.
+func transformStack(stack []*Delta, change *Delta) []*Delta {
+	remote := change
+	for i := len(stack) - 1; i >= 0; i-- {
+		old := stack[i]
+		stack[i] = remote.Transform(old, true)
+		remote = old.Transform(remote, false)
+		if stack[i].Length() == 0 {
+			stack = append(stack[:i], stack[i+1:]...)
+		}
+	}
+	return stack
+}
+
+// UndoStack returns the changes that Undo would apply, the next one last.
+func (h *History) UndoStack() []*Delta {
+	return append([]*Delta(nil), h.undo...)
+}
+
+// RedoStack returns the changes that Redo would apply, the next one last.
+func (h *History) RedoStack() []*Delta {
+	return append([]*Delta(nil), h.redo...)
+}
+
+// Clear forgets all of the recorded changes.
+func (h *History) Clear() {
+	h.undo, h.redo = nil, nil
+	h.Cutoff()
+}
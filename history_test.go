@@ -0,0 +1,141 @@
+package quill
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+
+	doc := new(Delta).Insert("Hello\n", nil)
+	h := NewHistory()
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	apply := func(change *Delta, at time.Duration) {
+		h.Record(change, doc, start.Add(at))
+		doc = doc.Compose(change)
+	}
+	undo := func() bool {
+		change, ok := h.Undo(doc)
+		if ok {
+			doc = doc.Compose(change)
+		}
+		return ok
+	}
+	redo := func() bool {
+		change, ok := h.Redo(doc)
+		if ok {
+			doc = doc.Compose(change)
+		}
+		return ok
+	}
+	check := func(step, want string) {
+		t.Helper()
+		if got := docText(doc); got != want {
+			t.Errorf("%s: got %q; want %q", step, got, want)
+		}
+	}
+
+	// The first two changes are made within the delay, so they are grouped.
+	apply(new(Delta).Retain(5, nil).Insert(" world", nil), 0)
+	apply(new(Delta).Retain(11, nil).Insert("!", nil), 500*time.Millisecond)
+	apply(new(Delta).Retain(5, attrs{"bold": true}), 3*time.Second)
+	apply(new(Delta).Delete(1).Insert("J", nil), 5*time.Second)
+	check("changes", "Jello world!\n")
+
+	if n := len(h.UndoStack()); n != 3 {
+		t.Errorf("got %d groups; want 3", n)
+	}
+
+	undo()
+	check("undo delete", "Hello world!\n")
+	undo()
+	check("undo bold", "Hello world!\n")
+	if len(doc.Ops) != 1 {
+		t.Errorf("the bold format was not undone")
+	}
+	undo()
+	check("undo group", "Hello\n")
+	if undo() {
+		t.Errorf("undid past the start")
+	}
+
+	redo()
+	check("redo group", "Hello world!\n")
+	redo()
+	if got, _ := json.Marshal(doc); string(got) != `[{"insert":"Hello","attributes":{"bold":true}},{"insert":" world!\n"}]` {
+		t.Errorf("redo bold: got %s", got)
+	}
+
+	// A new change clears the redo stack.
+	apply(new(Delta).Retain(12, nil).Insert("?", nil), 10*time.Second)
+	if redo() {
+		t.Errorf("redid after a new change")
+	}
+	check("new change", "Hello world!?\n")
+
+	// Cutoff stops grouping.
+	h.Cutoff()
+	apply(new(Delta).Retain(13, nil).Insert("?", nil), 10*time.Second+time.Millisecond)
+	undo()
+	check("undo after cutoff", "Hello world!?\n")
+
+}
+
+func TestHistory_MaxStack(t *testing.T) {
+	doc := new(Delta).Insert("\n", nil)
+	h := &History{MaxStack: 2}
+	for i := 0; i < 5; i++ {
+		change := new(Delta).Insert("x", nil)
+		h.Record(change, doc, time.Time{})
+		doc = doc.Compose(change)
+	}
+	if n := len(h.UndoStack()); n != 2 {
+		t.Errorf("got %d groups; want 2", n)
+	}
+}
+
+func TestHistory_Transform(t *testing.T) {
+
+	doc := new(Delta).Insert("abc\n", nil)
+	h := NewHistory()
+
+	// A local change, then a remote one that inserts before it.
+	local := new(Delta).Retain(3, nil).Insert("L", nil)
+	h.Record(local, doc, time.Now())
+	doc = doc.Compose(local)
+	remote := new(Delta).Insert("R", nil)
+	h.Transform(remote)
+	doc = doc.Compose(remote)
+
+	change, ok := h.Undo(doc)
+	if !ok {
+		t.Fatal("nothing to undo")
+	}
+	doc = doc.Compose(change)
+	if got := docText(doc); got != "Rabc\n" {
+		t.Errorf("undo after a remote change: got %q", got)
+	}
+
+	// A remote change that deletes what a change inserted makes it void.
+	h.Clear()
+	h.Record(local, doc, time.Now())
+	doc = doc.Compose(local)
+	h.Transform(new(Delta).Retain(3, nil).Delete(1))
+	if n := len(h.UndoStack()); n != 0 {
+		t.Errorf("got %d groups after the change was deleted; want 0", n)
+	}
+
+}
+
+// docText returns the text of a document Delta.
+func docText(d *Delta) string {
+	var s string
+	for _, op := range d.Ops {
+		if text, ok := op.Insert.(string); ok {
+			s += text
+		}
+	}
+	return s
+}
@@ -0,0 +1,684 @@
+package quill
+
+import (
+	"encoding/json"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// ImportHTML reads an HTML fragment, such as one written by Render, and returns the Delta that describes it as a JSON
+// array of insert operations that a Quill editor can load.
+func ImportHTML(src []byte) ([]byte, error) {
+	return json.Marshal(ParseHTML(src))
+}
+
+// ParseHTML reads an HTML fragment and splits its content into the blocks of a Document.
+//
+// The elements and classes that Render writes are recognized: paragraphs, headers, lists, code blocks, block quotes,
+// links, images, videos, formulas, dividers, page breaks and the inline formatting tags, the ql-align-*, ql-size-*,
+// ql-font-*, ql-direction-rtl and indent-* classes, the color and background-color styles, and the data-list and
+// data-language attributes of Quill 2. Other elements are read for their text only. The parser is forgiving, in the way
+// browsers are, of unclosed elements and unquoted attributes.
+func ParseHTML(src []byte) *Document {
+	im := htmlImporter{doc: new(Document)}
+	z := htmlTokenizer{s: string(src)}
+	for {
+		t, ok := z.next()
+		if !ok {
+			break
+		}
+		switch t.kind {
+		case htmlText:
+			im.text(t.data)
+		case htmlStartTag:
+			im.start(t)
+		case htmlEndTag:
+			im.end(t.name)
+		}
+	}
+	im.endLine(false)
+	return im.doc
+}
+
+// htmlBlockTags are the elements that start a new line.
+var htmlBlockTags = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "dd": true, "div": true, "dl": true,
+	"dt": true, "figcaption": true, "figure": true, "footer": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "section": true, "table": true, "td": true, "th": true, "tr": true, "ul": true,
+}
+
+// htmlListTags are the block elements that hold other blocks rather than text.
+var htmlListTags = map[string]bool{
+	"dl": true, "ol": true, "table": true, "tr": true, "ul": true,
+}
+
+// htmlVoidTags are the elements that never have any content or end tag.
+var htmlVoidTags = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true, "input": true,
+	"link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlSkipTags are the elements whose content is not part of the document.
+var htmlSkipTags = map[string]bool{
+	"head": true, "iframe": true, "script": true, "style": true, "template": true, "textarea": true, "title": true,
+}
+
+// An htmlImporter builds up a Document out of the tokens of an HTML fragment.
+type htmlImporter struct {
+	doc     *Document
+	stack   []htmlToken // the open elements
+	cur     *Block      // the line being read
+	started bool        // whether a block element was opened since the last line ended
+	trimPre bool        // whether a "\n" at the start of the pre element just opened is to be dropped
+
+	figureImage *Op // the image in the figure element being read, which takes the text of the figcaption as its caption
+}
+
+// start handles a start tag.
+func (im *htmlImporter) start(t htmlToken) {
+
+	switch t.name {
+	case "br":
+		im.endLine(true)
+		return
+	case "img":
+		if src := t.attrs["src"]; src != "" {
+			o := &Op{Data: src, Type: "image", Attrs: im.inlineAttrs()}
+			for _, a := range [...]string{"alt", "width", "height"} {
+				if v := t.attrs[a]; v != "" {
+					o.Attrs[a] = v
+				}
+			}
+			im.inline(o)
+			if im.isOpen("figure") {
+				im.figureImage = o
+			}
+		}
+		return
+	case "hr":
+		im.blockEmbed(&Op{Data: "y", Type: "divider", Attrs: make(map[string]string)})
+		return
+	case "div":
+		if t.hasClass("ql-page-break") {
+			im.blockEmbed(&Op{Data: "y", Type: "page-break", Attrs: make(map[string]string)})
+			if !t.selfClosing {
+				im.stack = append(im.stack, t)
+			}
+			return
+		}
+	case "figure":
+		im.figureImage = nil
+	case "figcaption":
+		if im.figureImage != nil {
+			im.stack = append(im.stack, t) // The caption is an attribute of the image, not a line of its own.
+			return
+		}
+	case "iframe":
+		if src := t.attrs["src"]; src != "" && t.hasClass("ql-video") {
+			im.inline(&Op{Data: src, Type: "video", Attrs: im.inlineAttrs()})
+		}
+	case "span":
+		if v := t.attrs["data-value"]; v != "" && t.hasClass("ql-formula") {
+			im.inline(&Op{Data: v, Type: "formula", Attrs: im.inlineAttrs()})
+		}
+	case "li", "p":
+		// An open element of the same kind is closed implicitly, but a list item may hold a nested list.
+		for i := len(im.stack) - 1; i >= 0; i-- {
+			n := im.stack[i].name
+			if n == t.name {
+				im.end(t.name)
+				break
+			}
+			if htmlBlockTags[n] {
+				break
+			}
+		}
+	}
+
+	if htmlBlockTags[t.name] {
+		if im.cur != nil {
+			im.endLine(false)
+		}
+		im.started = !htmlListTags[t.name]
+	}
+	if !htmlVoidTags[t.name] && !t.selfClosing {
+		im.stack = append(im.stack, t)
+	}
+
+	// Quill 2 starts the text of a code block, which has its language in a data-language attribute, with a "\n".
+	im.trimPre = t.name == "pre" && t.attrs["data-language"] != ""
+
+}
+
+// end handles an end tag by closing the innermost open element of the same name along with any elements that were
+// left open inside of it. An end tag without a matching open element is ignored.
+func (im *htmlImporter) end(name string) {
+	for i := len(im.stack) - 1; i >= 0; i-- {
+		if im.stack[i].name != name {
+			continue
+		}
+		for j := len(im.stack) - 1; j >= i; j-- {
+			if htmlBlockTags[im.stack[j].name] {
+				im.endLine(false)
+			}
+			im.stack = im.stack[:j]
+		}
+		return
+	}
+}
+
+// text handles the text between tags. Inside of a pre element the text is kept as it is and each "\n" ends a line;
+// elsewhere runs of white space are collapsed, as a browser would show them.
+func (im *htmlImporter) text(s string) {
+
+	if im.skipping() {
+		return
+	}
+
+	if im.figureImage != nil && im.isOpen("figcaption") {
+		caption := collapseSpace(im.figureImage.Attrs["caption"] + s)
+		im.figureImage.Attrs["caption"] = strings.TrimLeft(caption, " ")
+		return
+	}
+
+	if im.inPre() {
+		if im.trimPre {
+			s = strings.TrimPrefix(s, "\n")
+			im.trimPre = false
+		}
+		for {
+			i := strings.IndexByte(s, '\n')
+			if i == -1 {
+				break
+			}
+			im.addText(s[:i])
+			im.endLine(true)
+			s = s[i+1:]
+		}
+		im.addText(s)
+		return
+	}
+
+	s = collapseSpace(s)
+	if im.cur == nil || len(im.cur.Inlines) == 0 || im.endsWithSpace() {
+		s = strings.TrimLeft(s, " ")
+	}
+	im.addText(s)
+
+}
+
+// addText adds text with the inline formats of the open elements to the current line.
+func (im *htmlImporter) addText(s string) {
+	if s == "" {
+		return
+	}
+	im.inline(&Op{Data: s, Type: "text", Attrs: im.inlineAttrs()})
+}
+
+// inline adds an op to the current line, joining it to the previous text op if both have the same formats.
+func (im *htmlImporter) inline(o *Op) {
+	if im.cur == nil {
+		im.cur = &Block{Attrs: make(map[string]string)}
+	}
+	if n := len(im.cur.Inlines); n > 0 && o.Type == "text" {
+		if prev := im.cur.Inlines[n-1]; prev.Type == "text" && sameAttrs(prev.Attrs, o.Attrs) {
+			prev.Data += o.Data
+			return
+		}
+	}
+	im.cur.Inlines = append(im.cur.Inlines, o)
+}
+
+// blockEmbed adds an embed, such as a divider, that is a line of its own.
+func (im *htmlImporter) blockEmbed(o *Op) {
+	if im.cur != nil {
+		im.endLine(false)
+	}
+	im.inline(o)
+	im.endLine(true)
+}
+
+// endLine ends the current line, giving it the block formats of the open elements. Unless force is set, nothing is
+// done if no text was read and no block element was opened since the last line ended.
+func (im *htmlImporter) endLine(force bool) {
+	if im.cur == nil {
+		if !force && !im.started {
+			return
+		}
+		im.cur = new(Block)
+	}
+	if !im.inPre() {
+		// Trailing white space is not shown by browsers.
+		if n := len(im.cur.Inlines); n > 0 {
+			if last := im.cur.Inlines[n-1]; last.Type == "text" {
+				last.Data = strings.TrimRight(last.Data, " ")
+				if last.Data == "" {
+					im.cur.Inlines = im.cur.Inlines[:n-1]
+				}
+			}
+		}
+	}
+	im.cur.Attrs = im.blockAttrs()
+	im.doc.Blocks = append(im.doc.Blocks, im.cur)
+	im.cur = nil
+	im.started = false
+}
+
+// endsWithSpace says if the current line ends with a space.
+func (im *htmlImporter) endsWithSpace() bool {
+	n := len(im.cur.Inlines)
+	last := im.cur.Inlines[n-1]
+	return last.Type == "text" && strings.HasSuffix(last.Data, " ")
+}
+
+// inPre says if a pre element is open.
+func (im *htmlImporter) inPre() bool {
+	return im.isOpen("pre")
+}
+
+// isOpen says if an element with the name is open.
+func (im *htmlImporter) isOpen(name string) bool {
+	for i := range im.stack {
+		if im.stack[i].name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// skipping says if an element whose content is not shown, or is the rendering of a formula, is open.
+func (im *htmlImporter) skipping() bool {
+	for i := range im.stack {
+		if htmlSkipTags[im.stack[i].name] || im.stack[i].name == "span" && im.stack[i].hasClass("ql-formula") {
+			return true
+		}
+	}
+	return false
+}
+
+// inlineAttrs returns the inline formats set by the open elements.
+func (im *htmlImporter) inlineAttrs() map[string]string {
+	attrs := make(map[string]string)
+	inPre := false
+	for _, t := range im.stack {
+		switch t.name {
+		case "pre":
+			inPre = true
+		case "code":
+			if !inPre { // The code of a code block is often put in a code element as well.
+				attrs["code"] = "y"
+			}
+		case "a":
+			if href := t.attrs["href"]; href != "" {
+				attrs["link"] = href
+			}
+		case "b", "strong":
+			attrs["bold"] = "y"
+		case "em", "i":
+			attrs["italic"] = "y"
+		case "u", "ins":
+			attrs["underline"] = "y"
+		case "del", "s", "strike":
+			attrs["strike"] = "y"
+		case "sub":
+			attrs["script"] = "sub"
+		case "sup":
+			attrs["script"] = "super"
+		}
+		for _, c := range t.classes() {
+			switch {
+			case strings.HasPrefix(c, "ql-size-"):
+				attrs["size"] = c[len("ql-size-"):]
+			case strings.HasPrefix(c, "ql-font-"):
+				attrs["font"] = c[len("ql-font-"):]
+			}
+		}
+		if htmlBlockTags[t.name] {
+			continue
+		}
+		styles := t.styles()
+		if c := styles["color"]; c != "" {
+			attrs["color"] = c
+		}
+		if c := styles["background-color"]; c != "" {
+			attrs["background"] = c
+		} else if c = styles["background"]; c != "" {
+			attrs["background"] = c
+		}
+	}
+	return attrs
+}
+
+// blockAttrs returns the block formats set by the open elements.
+func (im *htmlImporter) blockAttrs() map[string]string {
+
+	attrs := make(map[string]string)
+	listType, depth := "", 0
+
+	for _, t := range im.stack {
+		switch t.name {
+		case "blockquote":
+			attrs["blockquote"] = "y"
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			attrs["header"] = t.name[1:]
+		case "pre":
+			attrs["code-block"] = "y"
+			if lang := t.attrs["data-language"]; lang != "" && lang != "plain" {
+				attrs["code-block"] = lang
+			}
+		case "ol":
+			listType = "ordered"
+			depth++
+		case "ul":
+			listType = "bullet"
+			depth++
+		case "li":
+			if listType == "" {
+				listType = "bullet"
+			}
+			attrs["list"] = listType
+			if l := t.attrs["data-list"]; l != "" {
+				attrs["list"] = l
+			}
+			if depth > 1 {
+				attrs["indent"] = strconv.Itoa(depth - 1)
+			}
+		}
+		if !htmlBlockTags[t.name] {
+			continue
+		}
+		for _, c := range t.classes() {
+			switch {
+			case strings.HasPrefix(c, "ql-align-"):
+				attrs["align"] = c[len("ql-align-"):]
+			case c == "ql-direction-rtl":
+				attrs["direction"] = "rtl"
+			case strings.HasPrefix(c, "indent-"), strings.HasPrefix(c, "ql-indent-"):
+				n, err := strconv.Atoi(c[strings.LastIndexByte(c, '-')+1:])
+				if err == nil && n > 0 {
+					if in, _ := strconv.Atoi(attrs["indent"]); in > 0 {
+						n += in
+					}
+					attrs["indent"] = strconv.Itoa(n)
+				}
+			}
+		}
+		if a := t.styles()["text-align"]; a != "" && a != "left" {
+			attrs["align"] = a
+		}
+		if strings.EqualFold(t.attrs["dir"], "rtl") {
+			attrs["direction"] = "rtl"
+		}
+	}
+
+	return attrs
+
+}
+
+// collapseSpace replaces each run of white space in s with a single space.
+func collapseSpace(s string) string {
+	var sb strings.Builder
+	space := false
+	for i := 0; i < len(s); i++ {
+		if isSpace(s[i]) {
+			space = true
+			continue
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteByte(s[i])
+	}
+	if space {
+		sb.WriteByte(' ')
+	}
+	return sb.String()
+}
+
+// sameAttrs says if the two sets of attributes are equal.
+func sameAttrs(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// The kinds of htmlToken.
+const (
+	htmlText = iota
+	htmlStartTag
+	htmlEndTag
+)
+
+// An htmlToken is a piece of text or a tag.
+type htmlToken struct {
+	kind        int
+	name        string            // the lower-case name of a tag
+	attrs       map[string]string // the attributes of a start tag
+	selfClosing bool              // whether a start tag ends with "/>"
+	data        string            // the unescaped text
+}
+
+// classes returns the values in the class attribute of the tag.
+func (t *htmlToken) classes() []string {
+	return strings.Fields(t.attrs["class"])
+}
+
+// hasClass says if the class attribute of the tag holds c.
+func (t *htmlToken) hasClass(c string) bool {
+	for _, tc := range t.classes() {
+		if tc == c {
+			return true
+		}
+	}
+	return false
+}
+
+// styles returns the declarations in the style attribute of the tag, by their lower-case property names.
+func (t *htmlToken) styles() map[string]string {
+	styles := make(map[string]string)
+	for _, decl := range strings.Split(t.attrs["style"], ";") {
+		i := strings.IndexByte(decl, ':')
+		if i == -1 {
+			continue
+		}
+		prop := strings.ToLower(strings.TrimSpace(decl[:i]))
+		if val := strings.TrimSpace(decl[i+1:]); prop != "" && val != "" {
+			styles[prop] = val
+		}
+	}
+	return styles
+}
+
+// An htmlTokenizer splits up an HTML fragment into text and tags. Comments, doctypes and processing instructions are
+// skipped, as is the content of script and style elements.
+type htmlTokenizer struct {
+	s       string
+	pos     int
+	rawText string // the name of an open element whose content is not parsed as HTML
+}
+
+// next returns the next token, or false at the end of the input.
+func (z *htmlTokenizer) next() (htmlToken, bool) {
+
+	for z.pos < len(z.s) {
+
+		if z.rawText != "" {
+			// Skip everything up to the end tag of the element.
+			i := indexFold(z.s[z.pos:], "</"+z.rawText)
+			z.rawText = ""
+			if i == -1 {
+				z.pos = len(z.s)
+				break
+			}
+			z.pos += i
+			continue
+		}
+
+		rest := z.s[z.pos:]
+		if rest[0] != '<' {
+			i := strings.IndexByte(rest, '<')
+			if i == -1 {
+				i = len(rest)
+			}
+			z.pos += i
+			return htmlToken{kind: htmlText, data: html.UnescapeString(rest[:i])}, true
+		}
+
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			i := strings.Index(rest[4:], "-->")
+			if i == -1 {
+				z.pos = len(z.s)
+			} else {
+				z.pos += 4 + i + 3
+			}
+			continue
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			z.skipPast('>')
+			continue
+		case strings.HasPrefix(rest, "</") && len(rest) > 2 && isLetter(rest[2]):
+			z.pos += 2
+			name := z.name()
+			z.skipPast('>')
+			return htmlToken{kind: htmlEndTag, name: name}, true
+		case len(rest) > 1 && isLetter(rest[1]):
+			z.pos++
+			t := z.startTag()
+			if htmlSkipTags[t.name] && !t.selfClosing {
+				z.rawText = t.name
+			}
+			return t, true
+		}
+
+		// A "<" that does not begin a tag is text.
+		z.pos++
+		return htmlToken{kind: htmlText, data: "<"}, true
+
+	}
+
+	return htmlToken{}, false
+
+}
+
+// startTag reads the name and attributes of a start tag.
+func (z *htmlTokenizer) startTag() htmlToken {
+
+	t := htmlToken{kind: htmlStartTag, name: z.name(), attrs: make(map[string]string)}
+
+	for z.pos < len(z.s) {
+		z.skipSpace()
+		if z.pos >= len(z.s) {
+			break
+		}
+		switch c := z.s[z.pos]; {
+		case c == '>':
+			z.pos++
+			return t
+		case c == '/':
+			z.pos++
+			if z.pos < len(z.s) && z.s[z.pos] == '>' {
+				t.selfClosing = true
+				z.pos++
+				return t
+			}
+			continue
+		}
+		key := z.attrName()
+		z.skipSpace()
+		val := ""
+		if z.pos < len(z.s) && z.s[z.pos] == '=' {
+			z.pos++
+			z.skipSpace()
+			val = html.UnescapeString(z.attrValue())
+		}
+		if _, ok := t.attrs[key]; !ok && key != "" {
+			t.attrs[key] = val
+		}
+	}
+
+	return t
+
+}
+
+// name reads a tag name.
+func (z *htmlTokenizer) name() string {
+	start := z.pos
+	for z.pos < len(z.s) && !isSpace(z.s[z.pos]) && z.s[z.pos] != '/' && z.s[z.pos] != '>' {
+		z.pos++
+	}
+	return strings.ToLower(z.s[start:z.pos])
+}
+
+// attrName reads an attribute name.
+func (z *htmlTokenizer) attrName() string {
+	start := z.pos
+	for z.pos < len(z.s) {
+		c := z.s[z.pos]
+		if isSpace(c) || c == '=' || c == '>' || (c == '/' && z.pos > start) {
+			break
+		}
+		z.pos++
+	}
+	return strings.ToLower(z.s[start:z.pos])
+}
+
+// attrValue reads a quoted or unquoted attribute value.
+func (z *htmlTokenizer) attrValue() string {
+	if z.pos >= len(z.s) {
+		return ""
+	}
+	if q := z.s[z.pos]; q == '"' || q == '\'' {
+		z.pos++
+		i := strings.IndexByte(z.s[z.pos:], q)
+		if i == -1 {
+			i = len(z.s) - z.pos
+		}
+		v := z.s[z.pos : z.pos+i]
+		z.pos += i + 1
+		return v
+	}
+	start := z.pos
+	for z.pos < len(z.s) && !isSpace(z.s[z.pos]) && z.s[z.pos] != '>' {
+		z.pos++
+	}
+	return z.s[start:z.pos]
+}
+
+// skipSpace moves past any white space.
+func (z *htmlTokenizer) skipSpace() {
+	for z.pos < len(z.s) && isSpace(z.s[z.pos]) {
+		z.pos++
+	}
+}
+
+// skipPast moves past the next c, or to the end of the input.
+func (z *htmlTokenizer) skipPast(c byte) {
+	i := strings.IndexByte(z.s[z.pos:], c)
+	if i == -1 {
+		z.pos = len(z.s)
+		return
+	}
+	z.pos += i + 1
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// indexFold is strings.Index for an ASCII substr, ignoring case.
+func indexFold(s, substr string) int {
+	return strings.Index(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,157 @@
+package quill
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportHTML_roundTrip(t *testing.T) {
+
+	files, err := filepath.Glob("./testdata/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range files {
+		ops, err := ioutil.ReadFile(f)
+		if err != nil {
+			t.Fatalf("could not read %q: %s", f, err)
+		}
+		html, err := Render(ops)
+		if err != nil {
+			t.Fatalf("%s: %s", f, err)
+		}
+		got, err := ImportHTML(html)
+		if err != nil {
+			t.Fatalf("%s: %s", f, err)
+		}
+		if !sameJSON(t, got, ops) {
+			t.Errorf("%s: bad round trip of %s\ngot:  %s", f, html, got)
+		}
+	}
+
+}
+
+func TestImportHTML(t *testing.T) {
+
+	cases := map[string]struct {
+		html string
+		want string
+	}{
+		"plain text": {
+			html: "some text",
+			want: `[{"insert":"some text\n"}]`,
+		},
+		"empty paragraph": {
+			html: "<p>one</p><p><br></p><p>three</p>",
+			want: `[{"insert":"one\n\nthree\n"}]`,
+		},
+		"line breaks": {
+			html: "<p>one<br>two<br/><br>four<br></p>",
+			want: `[{"insert":"one\ntwo\n\nfour\n"}]`,
+		},
+		"white space": {
+			html: "<div>\n  <p>  some\n\ttext <b> bold </b> </p>\n</div>",
+			want: `[{"insert":"some text "},{"attributes":{"bold":true},"insert":"bold"},{"insert":"\n"}]`,
+		},
+		"entities": {
+			html: "<p>a &lt;b&gt; &amp;amp; &quot;c&quot;&nbsp;d</p>",
+			want: `[{"insert":"a <b> &amp; \"c\"` + " " + `d\n"}]`,
+		},
+		"unclosed elements": {
+			html: "<p>one<p>two<ul><li>a<li>b</ul>",
+			want: `[{"insert":"one\ntwo\na"},{"attributes":{"list":"bullet"},"insert":"\n"},{"insert":"b"},` +
+				`{"attributes":{"list":"bullet"},"insert":"\n"}]`,
+		},
+		"nested lists": {
+			html: "<ol><li>a<ul><li>b<ol><li>c</li></ol></li></ul></li><li>d</li></ol>",
+			want: `[{"insert":"a"},{"attributes":{"list":"ordered"},"insert":"\n"},{"insert":"b"},` +
+				`{"attributes":{"indent":1,"list":"bullet"},"insert":"\n"},{"insert":"c"},` +
+				`{"attributes":{"indent":2,"list":"ordered"},"insert":"\n"},{"insert":"d"},` +
+				`{"attributes":{"list":"ordered"},"insert":"\n"}]`,
+		},
+		"unquoted and upper-case attributes": {
+			html: `<P CLASS=ql-align-center><A HREF=https://example.com/>link</A></P>`,
+			want: `[{"attributes":{"link":"https://example.com/"},"insert":"link"},` +
+				`{"attributes":{"align":"center"},"insert":"\n"}]`,
+		},
+		"styles": {
+			html: `<p style="text-align: right"><span style="color: #a10000; background-color:#ffff00">c</span></p>`,
+			want: `[{"attributes":{"background":"#ffff00","color":"#a10000"},"insert":"c"},` +
+				`{"attributes":{"align":"right"},"insert":"\n"}]`,
+		},
+		"scripts and sizes": {
+			html: `<p>H<sub>2</sub>O x<sup>2</sup> <span class="ql-size-huge">big</span></p>`,
+			want: `[{"insert":"H"},{"attributes":{"script":"sub"},"insert":"2"},{"insert":"O x"},` +
+				`{"attributes":{"script":"super"},"insert":"2"},{"insert":" "},` +
+				`{"attributes":{"size":"huge"},"insert":"big"},{"insert":"\n"}]`,
+		},
+		"code, fonts and direction": {
+			html: `<p class="ql-direction-rtl"><code>x</code> <span class="ql-font-serif">y</span></p><p dir="rtl">z</p>` +
+				`<pre><code>w</code></pre>`,
+			want: `[{"attributes":{"code":true},"insert":"x"},{"insert":" "},{"attributes":{"font":"serif"},"insert":"y"},` +
+				`{"attributes":{"direction":"rtl"},"insert":"\n"},{"insert":"z"},{"attributes":{"direction":"rtl"},"insert":"\n"},` +
+				`{"insert":"w"},{"attributes":{"code-block":true},"insert":"\n"}]`,
+		},
+		"image in link": {
+			html: `<p><a href="/to"><img src="/img.png" alt="an image"></a></p>`,
+			want: `[{"attributes":{"alt":"an image","link":"/to"},"insert":{"image":"/img.png"}},{"insert":"\n"}]`,
+		},
+		"figure": {
+			html: `<p>a</p><figure><img src="/img.png" width="30"><figcaption>the <i>caption</i></figcaption></figure><p>b</p>`,
+			want: `[{"insert":"a\n"},{"attributes":{"caption":"the caption","width":"30"},"insert":{"image":"/img.png"}},` +
+				`{"insert":"\nb\n"}]`,
+		},
+		"skipped content": {
+			html: "<!DOCTYPE html><html><head><title>T</title><style>p{}</style></head>" +
+				"<body><!-- c --><p>text</p><script>if (a<b) {}</script></body></html>",
+			want: `[{"insert":"text\n"}]`,
+		},
+		"formats in code": {
+			html: "<pre><span>a &lt; b</span>\n\n  c\n</pre>",
+			want: `[{"insert":"a < b"},{"attributes":{"code-block":true},"insert":"\n\n"},{"insert":"  c"},` +
+				`{"attributes":{"code-block":true},"insert":"\n"}]`,
+		},
+	}
+
+	for name, c := range cases {
+		got, err := ImportHTML([]byte(c.html))
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if !sameJSON(t, got, []byte(c.want)) {
+			t.Errorf("%s: bad import of %q\ngot:  %s\nwant: %s", name, c.html, got, c.want)
+		}
+	}
+
+}
+
+func TestImportHTML_text(t *testing.T) {
+	// Text that is not in any element is a paragraph of its own.
+	doc := ParseHTML([]byte("<h2>title</h2>after"))
+	if len(doc.Blocks) != 2 || doc.Blocks[1].Text() != "after" || len(doc.Blocks[1].Attrs) != 0 {
+		var sb strings.Builder
+		for _, b := range doc.Blocks {
+			sb.WriteString(b.Text() + "|")
+		}
+		t.Errorf("bad blocks: %s", sb.String())
+	}
+}
+
+// sameJSON says if the two JSON documents hold the same values.
+func sameJSON(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var av, bv interface{}
+	if err := json.Unmarshal(a, &av); err != nil {
+		t.Fatalf("bad JSON %s: %s", a, err)
+	}
+	if err := json.Unmarshal(b, &bv); err != nil {
+		t.Fatalf("bad JSON %s: %s", b, err)
+	}
+	return reflect.DeepEqual(av, bv)
+}
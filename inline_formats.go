@@ -2,7 +2,6 @@ package quill
 
 import (
 	"io"
-	"strconv"
 	"strings"
 )
 
@@ -48,14 +47,49 @@ func (*underlineFormat) HasFormat(o *Op) bool {
 	return o.HasAttr("underline")
 }
 
+// inline code
+type codeFormat struct{}
+
+func (*codeFormat) Fmt() *Format {
+	return &Format{
+		Val:   "code",
+		Place: Tag,
+	}
+}
+
+func (*codeFormat) HasFormat(o *Op) bool {
+	return o.HasAttr("code")
+}
+
 // text color
 type colorFormat struct {
-	c string
+	c   string // the color as given by the Delta
+	css string // the color to write out
+}
+
+// newColorFormat returns the format for the color or background attribute (given by attr) set on o, or nil if the
+// policy does not allow the color.
+func newColorFormat(attr string, o *Op, p *ColorPolicy) Formatter {
+	c := o.Attrs[attr]
+	css, ok := p.Color(c)
+	if !ok {
+		return nil
+	}
+	if attr == "background" {
+		return &bkgFormat{
+			c:   c,
+			css: css,
+		}
+	}
+	return &colorFormat{
+		c:   c,
+		css: css,
+	}
 }
 
 func (cf *colorFormat) Fmt() *Format {
 	return &Format{
-		Val:   "color:" + cf.c + ";",
+		Val:   "color:" + cf.css + ";",
 		Place: Style,
 	}
 }
@@ -66,7 +100,23 @@ func (cf *colorFormat) HasFormat(o *Op) bool {
 
 // link
 type linkFormat struct {
-	href string
+	href string // the URL as given by the Delta
+	url  string // the URL to write out
+	rel  string // the rel attribute, if it is not set by the kind of URL
+}
+
+// newLinkFormat returns the format for the link set on o if the policy allows the link URL. Otherwise, it returns a
+// Formatter that leaves the text unlinked.
+func newLinkFormat(o *Op, p *URLPolicy) Formatter {
+	href := o.Attrs["link"]
+	u, ok := p.Resolve(href, LinkURL)
+	if !ok {
+		return noFormat{}
+	}
+	return &linkFormat{
+		href: href,
+		url:  u,
+	}
 }
 
 func (*linkFormat) Fmt() *Format { return new(Format) } // Only a wrapper.
@@ -77,15 +127,24 @@ func (lf *linkFormat) HasFormat(*Op) bool {
 
 func (lf *linkFormat) Wrap() (string, string) {
 
+	if lf.rel != "" {
+		return `<a href=` + quoteAttr(lf.url) + ` rel=` + quoteAttr(lf.rel) + ` target="_blank">`, "</a>"
+	}
 	if strings.HasPrefix(lf.href, "/") {
-		return `<a href=` + strconv.Quote(lf.href) + ` target="_blank">`, "</a>"
+		return `<a href=` + quoteAttr(lf.url) + ` target="_blank">`, "</a>"
 	} else {
-		return `<a href=` + strconv.Quote(lf.href) + ` target="_blank" rel="nofollow noopener">`, "</a>"
+		return `<a href=` + quoteAttr(lf.url) + ` target="_blank" rel="nofollow noopener">`, "</a>"
 	}
 }
 
-func (lf *linkFormat) Open(_ []*Format, _ *Op) bool {
-	return true // This format will only appear when there is a "link" attribute set.
+func (lf *linkFormat) Open(open []*Format, _ *Op) bool {
+	// This format will only appear when there is a "link" attribute set, but the same link may already be open.
+	for i := range open {
+		if olf, ok := open[i].fm.(*linkFormat); ok && open[i].wrap && olf.href == lf.href {
+			return false
+		}
+	}
+	return true
 }
 
 func (lf *linkFormat) Close(_ []*Format, o *Op, _ bool) bool {
@@ -94,7 +153,35 @@ func (lf *linkFormat) Close(_ []*Format, o *Op, _ bool) bool {
 
 // image
 type imageFormat struct {
-	src, alt string
+	src, alt      string
+	width, height string // the dimensions in pixels, if they are given
+	caption       string // the caption, which puts the image in a figure
+}
+
+// newImageFormat returns the format for the image embedded by o. If the policy blocks the image URL, the placeholder
+// image of the policy is used, and if there is no placeholder then nothing is written for the image.
+func newImageFormat(o *Op, p *URLPolicy) Formatter {
+	src, ok := p.Resolve(o.Data, ImageURL)
+	if !ok {
+		src = p.ImagePlaceholder
+	}
+	return &imageFormat{
+		src:     src,
+		alt:     o.Attrs["alt"],
+		width:   imageDimension(o.Attrs["width"]),
+		height:  imageDimension(o.Attrs["height"]),
+		caption: strings.TrimSpace(o.Attrs["caption"]),
+	}
+}
+
+// imageDimension returns the number of pixels given by a width or height such as "300" or "300px", or "" if it is not
+// a number of pixels.
+func imageDimension(v string) string {
+	v = strings.TrimSuffix(strings.TrimSpace(v), "px")
+	if v == "" || strings.Trim(v, "0123456789") != "" {
+		return ""
+	}
+	return v
 }
 
 func (*imageFormat) Fmt() *Format { return nil } // The body contains the entire element.
@@ -105,13 +192,30 @@ func (imf *imageFormat) HasFormat(o *Op) bool {
 
 // imageFormat implements the FormatWriter interface.
 func (imf *imageFormat) Write(buf io.Writer) {
+	if imf.src == "" {
+		return // The image is blocked.
+	}
+	if imf.caption != "" {
+		io.WriteString(buf, "<figure>")
+	}
 	io.WriteString(buf, "<img src=")
-	io.WriteString(buf, strconv.Quote(imf.src))
+	io.WriteString(buf, quoteAttr(imf.src))
 	if imf.alt != "" {
 		io.WriteString(buf, " alt=")
-		io.WriteString(buf, strconv.Quote(imf.alt))
+		io.WriteString(buf, quoteAttr(imf.alt))
+	}
+	if imf.width != "" {
+		io.WriteString(buf, ` width="`+imf.width+`"`)
+	}
+	if imf.height != "" {
+		io.WriteString(buf, ` height="`+imf.height+`"`)
+	}
+	io.WriteString(buf, ` loading="lazy">`)
+	if imf.caption != "" {
+		io.WriteString(buf, "<figcaption>")
+		textEscaper.WriteString(buf, imf.caption)
+		io.WriteString(buf, "</figcaption></figure>")
 	}
-	buf.Write([]byte{'>'})
 }
 
 // strikethrough
@@ -130,12 +234,13 @@ func (*strikeFormat) HasFormat(o *Op) bool {
 
 // background
 type bkgFormat struct {
-	c string
+	c   string // the color as given by the Delta
+	css string // the color to write out
 }
 
 func (bf *bkgFormat) Fmt() *Format {
 	return &Format{
-		Val:   "background-color:" + bf.c + ";",
+		Val:   "background-color:" + bf.css + ";",
 		Place: Style,
 	}
 }
@@ -145,17 +250,64 @@ func (bf *bkgFormat) HasFormat(o *Op) bool {
 }
 
 // sizeFormat is used for inline strings of named sizes such as "huge" or "small".
-type sizeFormat string
+type sizeFormat struct {
+	val   string
+	class string // the classes of the size
+	style string // the font size written in place of the class, if inline styles are used
+}
+
+func (sf *sizeFormat) Fmt() *Format {
+	if sf.style != "" {
+		return &Format{
+			Val:   "font-size:" + sf.style + ";",
+			Place: Style,
+		}
+	}
+	return &Format{
+		Val:   sf.class,
+		Place: Class,
+	}
+}
+
+func (sf *sizeFormat) HasFormat(o *Op) bool {
+	return o.Attrs["size"] == sf.val
+}
+
+// fontFormat is used for inline strings of the fonts that Quill offers other than the default sans-serif font.
+type fontFormat struct {
+	val   string
+	class string // the classes of the font
+	style string // the font family written in place of the class, if inline styles are used
+}
 
-func (sf sizeFormat) Fmt() *Format {
+func (ff *fontFormat) Fmt() *Format {
+	if ff.style != "" {
+		return &Format{
+			Val:   "font-family:" + ff.style + ";",
+			Place: Style,
+		}
+	}
 	return &Format{
-		Val:   "ql-size-" + string(sf),
+		Val:   ff.class,
 		Place: Class,
 	}
 }
 
-func (sf sizeFormat) HasFormat(o *Op) bool {
-	return o.Attrs["size"] == string(sf)
+func (ff *fontFormat) HasFormat(o *Op) bool {
+	return o.Attrs["font"] == ff.val
+}
+
+// quillFonts lists the values of the font attribute that Quill allows by default.
+var quillFonts = map[string]bool{
+	"serif":     true,
+	"monospace": true,
+}
+
+// sizeStyles gives the font size of each named size in Quill's default theme.
+var sizeStyles = map[string]string{
+	"small": "0.75em",
+	"large": "1.5em",
+	"huge":  "2.5em",
 }
 
 // script (sup and sub)
@@ -171,6 +323,9 @@ func (sf *scriptFormat) Fmt() *Format {
 	}
 }
 
-func (*scriptFormat) HasFormat(o *Op) bool {
-	return o.HasAttr("script")
+func (sf *scriptFormat) HasFormat(o *Op) bool {
+	if sf.t == "sup" {
+		return o.Attrs["script"] == "super"
+	}
+	return o.Attrs["script"] == "sub"
 }
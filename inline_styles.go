@@ -0,0 +1,81 @@
+package quill
+
+import "strconv"
+
+// InlineStyles gives the CSS values that are written in style attributes in place of the classes that need Quill's
+// stylesheet. Values of the size, align, indent and font attributes that are not in the maps are not written, and
+// neither are sizes, alignments and indents that Quill does not allow.
+type InlineStyles struct {
+	Sizes   map[string]string // the font-size of each value of the size attribute (such as "huge")
+	Aligns  map[string]string // the text-align of each value of the align attribute (such as "center")
+	Indents map[string]string // the padding of each value of the indent attribute (such as "3")
+	// ListIndents gives the padding of list items for each value of the indent attribute. If it is nil, list items are
+	// indented like other blocks, with Indents.
+	ListIndents map[string]string
+	Fonts       map[string]string // the font-family of each value of the font attribute (such as "serif")
+}
+
+// QuillStyles returns the InlineStyles that match the default theme of Quill. The InlineStyles returned may be changed
+// and given to WithInlineStyles.
+func QuillStyles() *InlineStyles {
+	s := &InlineStyles{
+		Sizes: make(map[string]string, len(sizeStyles)),
+		Aligns: map[string]string{
+			"center":  "center",
+			"right":   "right",
+			"justify": "justify",
+		},
+		Indents:     make(map[string]string, 8),
+		ListIndents: make(map[string]string, 8),
+		Fonts: map[string]string{
+			"serif":     "Georgia, Times New Roman, serif",
+			"monospace": "Monaco, Courier New, monospace",
+		},
+	}
+	for k, v := range sizeStyles {
+		s.Sizes[k] = v
+	}
+	for i := 1; i <= 8; i++ { // Quill indents each level by 3em, up to eight levels.
+		s.Indents[strconv.Itoa(i)] = strconv.Itoa(3*i) + "em"
+		s.ListIndents[strconv.Itoa(i)] = strconv.Itoa(3*i+1) + ".5em" // List items have another 1.5em for the marker.
+	}
+	return s
+}
+
+// WithInlineStyles makes the Renderer write the size, align, indent, font and direction attributes as inline styles, so
+// that the HTML looks right where Quill's stylesheet is not loaded: sizes as a font-size, alignments as a text-align,
+// indents as a padding-left (or, for right-to-left text, a padding-right), fonts as a font-family, and right-to-left
+// text as a direction. If s is nil, the values of QuillStyles are used.
+func WithInlineStyles(s *InlineStyles) Option {
+	if s == nil {
+		s = QuillStyles()
+	} else {
+		s = s.clone() // The Renderer may be used concurrently while the caller changes s.
+	}
+	return func(r *Renderer) {
+		r.styles = s
+	}
+}
+
+// clone returns a deep copy of the InlineStyles.
+func (s *InlineStyles) clone() *InlineStyles {
+	return &InlineStyles{
+		Sizes:       cloneStrings(s.Sizes),
+		Aligns:      cloneStrings(s.Aligns),
+		Indents:     cloneStrings(s.Indents),
+		ListIndents: cloneStrings(s.ListIndents),
+		Fonts:       cloneStrings(s.Fonts),
+	}
+}
+
+// cloneStrings returns a copy of m, which is nil if m is nil.
+func cloneStrings(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
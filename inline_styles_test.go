@@ -0,0 +1,87 @@
+package quill
+
+import "testing"
+
+func TestInlineStyles(t *testing.T) {
+
+	custom := QuillStyles()
+	custom.Sizes["huge"] = "32px"
+	custom.Indents = map[string]string{"1": "20px"}
+
+	cases := map[string]struct {
+		opts []Option
+		ops  string
+		want string
+	}{
+		"size": {
+			ops:  `[{"insert":"big","attributes":{"size":"huge"}},{"insert":" "},{"insert":"odd","attributes":{"size":"tiny"}},{"insert":"\n"}]`,
+			want: `<p><span style="font-size:2.5em;">big</span> odd</p>`,
+		},
+		"align and indent": {
+			ops:  `[{"insert":"a"},{"insert":"\n","attributes":{"align":"center","indent":2}}]`,
+			want: `<p style="padding-left:6em;text-align:center;">a</p>`,
+		},
+		"right-to-left indent": {
+			ops:  `[{"insert":"a"},{"insert":"\n","attributes":{"direction":"rtl","align":"right","indent":1}}]`,
+			want: `<p style="direction:rtl;padding-right:3em;text-align:right;">a</p>`,
+		},
+		"font": {
+			ops:  `[{"insert":"a","attributes":{"font":"monospace"}},{"insert":"b","attributes":{"font":"comic"}},{"insert":"\n"}]`,
+			want: `<p><span style="font-family:Monaco, Courier New, monospace;">a</span>b</p>`,
+		},
+		"list item": {
+			ops:  `[{"insert":"a"},{"insert":"\n","attributes":{"list":"bullet","indent":1}}]`,
+			want: `<ul><li style="padding-left:4.5em;">a</li></ul>`,
+		},
+		"deeper list item": {
+			ops:  `[{"insert":"a"},{"insert":"\n","attributes":{"list":"ordered","indent":3}}]`,
+			want: `<ol><li style="padding-left:10.5em;">a</li></ol>`,
+		},
+		"aligned page break": {
+			ops:  `[{"insert":{"page-break":true}},{"insert":"\n","attributes":{"align":"center"}}]`,
+			want: `<div style="text-align:center;break-after:page;page-break-after:always"></div>`,
+		},
+		"unknown values dropped": {
+			ops:  `[{"insert":"a"},{"insert":"\n","attributes":{"align":"left","indent":9}}]`,
+			want: `<p>a</p>`,
+		},
+		"list items indented like blocks": {
+			opts: []Option{WithInlineStyles(&InlineStyles{Indents: map[string]string{"1": "2em"}})},
+			ops:  `[{"insert":"a"},{"insert":"\n","attributes":{"list":"bullet","indent":1}}]`,
+			want: `<ul><li style="padding-left:2em;">a</li></ul>`,
+		},
+		"custom mapping": {
+			opts: []Option{WithInlineStyles(custom)},
+			ops:  `[{"insert":"big","attributes":{"size":"huge"}},{"insert":"\n","attributes":{"indent":1}},{"insert":"b"},{"insert":"\n","attributes":{"indent":2}}]`,
+			want: `<p style="padding-left:20px;"><span style="font-size:32px;">big</span></p><p>b</p>`,
+		},
+	}
+
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			opts := tc.opts
+			if opts == nil {
+				opts = []Option{WithInlineStyles(nil)}
+			}
+			got, err := NewRenderer(opts...).Render([]byte(tc.ops))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("bad rendering\ngot:  %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+
+	// The Renderer keeps its own copy of the mapping.
+	r := NewRenderer(WithInlineStyles(custom))
+	custom.Sizes["huge"] = "1px"
+	got, err := r.Render([]byte(`[{"insert":"big","attributes":{"size":"huge"}},{"insert":"\n"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<p><span style="font-size:32px;">big</span></p>`; string(got) != want {
+		t.Errorf("mapping changed after being set; got: %s", got)
+	}
+
+}
@@ -0,0 +1,524 @@
+package quill
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// A MarkdownFallback says what is written in Markdown for formats that Markdown cannot express, such as color, size,
+// underline, sub/superscript, and text alignment.
+type MarkdownFallback uint8
+
+const (
+	// DropUnsupported writes the text without the formats that Markdown cannot express.
+	DropUnsupported MarkdownFallback = iota
+
+	// HTMLUnsupported writes the formats that Markdown cannot express as inline HTML with inline styles. Aligned
+	// paragraphs and headers are wrapped in a <div> with an align attribute, and embeds other than images are written
+	// as their HTML.
+	HTMLUnsupported
+)
+
+// WithMarkdownFallback sets what RenderMarkdown writes for formats that Markdown cannot express. The default is
+// DropUnsupported.
+func WithMarkdownFallback(f MarkdownFallback) Option {
+	return func(r *Renderer) {
+		r.mdFallback = f
+	}
+}
+
+// RenderMarkdown takes a Delta array of insert operations and returns it as Markdown (CommonMark with the GitHub
+// Flavored Markdown extensions for strikethrough and task lists) using the built-in settings.
+func RenderMarkdown(ops []byte) ([]byte, error) {
+	return defaultRenderer.RenderMarkdown(ops)
+}
+
+// RenderMarkdown takes a Delta array of insert operations and returns it as Markdown (CommonMark with the GitHub
+// Flavored Markdown extensions for strikethrough and task lists).
+//
+// Bold, italic, strikethrough, links, images, headers, blockquotes, lists (nested by their indent), code blocks, and
+// dividers are written as Markdown. What is written for other formats is set with WithMarkdownFallback. The URL and color
+// policies of the Renderer apply, and formats removed from its Registry are not written. Custom formats, which write
+// HTML only, are ignored.
+func (r *Renderer) RenderMarkdown(ops []byte) ([]byte, error) {
+	doc, err := r.Parse(ops)
+	if err != nil {
+		return nil, err
+	}
+	return r.RenderDocumentMarkdown(doc), nil
+}
+
+// RenderDocumentMarkdown returns the Document as Markdown. See RenderMarkdown.
+func (r *Renderer) RenderDocumentMarkdown(doc *Document) []byte {
+
+	w := mdWriter{r: r}
+
+	for _, g := range doc.Groups() {
+		mark := w.buf.Len()
+		if mark > 0 {
+			w.buf.WriteByte('\n') // Separate groups with a blank line.
+		}
+		switch g.Kind {
+		case ListGroup:
+			w.writeList(g.Blocks)
+		case CodeGroup:
+			w.writeCode(g.Blocks)
+		default:
+			w.writeBlock(g.Blocks[0])
+		}
+		if w.buf.Len() <= mark+1 {
+			w.buf.Truncate(mark) // Nothing was written.
+		}
+	}
+
+	return w.buf.Bytes()
+
+}
+
+// An mdWriter writes a Document as Markdown.
+type mdWriter struct {
+	r       *Renderer
+	buf     bytes.Buffer
+	open    []mdMark // the inline marks currently open, outermost first
+	pending []mdMark // marks to open right before the next text that is not whitespace
+	start   int      // the position in buf where the content of the current line starts
+}
+
+// An mdMark is an inline format written as opening and closing strings around text.
+type mdMark struct {
+	rank        int    // the nesting order of the mark (lower is outer)
+	key         string // identifies the mark, including its value
+	open, close string
+}
+
+// writeBlock writes a block that is not a list item or a line of code.
+func (w *mdWriter) writeBlock(b *Block) {
+
+	var prefix string
+	switch {
+	case b.HasAttr("header"):
+		level, _ := strconv.Atoi(b.Attrs["header"])
+		if level < 1 {
+			level = 1
+		} else if level > 6 {
+			level = 6
+		}
+		prefix = strings.Repeat("#", level) + " "
+	case b.HasAttr("blockquote"):
+		prefix = "> "
+	}
+
+	if len(b.Inlines) == 0 {
+		return // Markdown cannot express empty paragraphs.
+	}
+
+	if len(b.Inlines) == 1 && b.Inlines[0].Type == "divider" && w.r.formats.Lookup("divider") != nil {
+		w.buf.WriteString("---\n") // a thematic break
+		return
+	}
+
+	align := ""
+	if w.r.mdFallback == HTMLUnsupported && w.r.formats.Lookup("align") != nil {
+		switch a := b.Attrs["align"]; a {
+		case "center", "right", "justify":
+			align = a
+		}
+	}
+
+	start := w.buf.Len()
+	if align != "" {
+		w.buf.WriteString(`<div align="` + align + "\">\n\n")
+	}
+
+	w.buf.WriteString(prefix)
+	lineStart := w.buf.Len()
+	w.writeInlines(b)
+	if w.buf.Len() == lineStart {
+		w.buf.Truncate(start) // Only embeds that are not written were in the block.
+		return
+	}
+	w.buf.WriteByte('\n')
+
+	if align != "" {
+		w.buf.WriteString("\n</div>\n")
+	}
+
+}
+
+// A mdListLevel holds the state of a list at one indent level.
+type mdListLevel struct {
+	lType  string // the value of the list attribute
+	num    int    // the number of the last ordered item
+	indent int    // the number of spaces before the item marker
+	width  int    // the width of the item marker, which is how much deeper nested lists are indented
+}
+
+// writeList writes the items of a list, nesting them by their indent.
+func (w *mdWriter) writeList(items []*Block) {
+
+	var levels []mdListLevel
+
+	for _, b := range items {
+
+		depth, _ := strconv.Atoi(b.Attrs["indent"])
+		if depth < 0 {
+			depth = 0
+		} else if depth > len(levels) {
+			depth = len(levels) // A list cannot be nested more than one level deeper than its parent.
+		}
+
+		lType := b.Attrs["list"]
+		if depth < len(levels) {
+			levels = levels[:depth+1]
+			if levels[depth].lType != lType {
+				levels[depth] = mdListLevel{lType: lType, indent: levels[depth].indent}
+			}
+		} else {
+			lvl := mdListLevel{lType: lType}
+			if depth > 0 {
+				parent := levels[depth-1]
+				lvl.indent = parent.indent + parent.width
+			}
+			levels = append(levels, lvl)
+		}
+
+		lvl := &levels[depth]
+		var marker string
+		switch lType {
+		case "ordered":
+			lvl.num++
+			marker = strconv.Itoa(lvl.num) + ". "
+		case "checked":
+			marker = "- [x] "
+		case "unchecked":
+			marker = "- [ ] "
+		default:
+			marker = "- "
+		}
+		lvl.width = len(marker)
+		if lType == "checked" || lType == "unchecked" {
+			lvl.width = 2 // Nested lists are aligned with the task box.
+		}
+
+		w.buf.WriteString(strings.Repeat(" ", lvl.indent))
+		w.buf.WriteString(marker)
+		w.writeInlines(b)
+		w.buf.WriteByte('\n')
+
+	}
+
+}
+
+// writeCode writes the lines of a code block as a fenced code block.
+func (w *mdWriter) writeCode(lines []*Block) {
+
+	var code strings.Builder
+	for i, b := range lines {
+		if i > 0 {
+			code.WriteByte('\n')
+		}
+		code.WriteString(b.Text())
+	}
+
+	fence := backtickFence(code.String(), 3)
+
+	lang := lines[0].Attrs["code-block"]
+	if lang == "y" || lang == "plain" || strings.ContainsAny(lang, "` \t") {
+		lang = ""
+	}
+
+	w.buf.WriteString(fence)
+	w.buf.WriteString(lang)
+	w.buf.WriteByte('\n')
+	w.buf.WriteString(code.String())
+	w.buf.WriteByte('\n')
+	w.buf.WriteString(fence)
+	w.buf.WriteByte('\n')
+
+}
+
+// backtickFence returns a fence of at least min backticks that is longer than any run of backticks in s.
+func backtickFence(s string, min int) string {
+	fence, run := min, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '`' {
+			if run++; run >= fence {
+				fence = run + 1
+			}
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", fence)
+}
+
+// writeInlines writes the text runs and embeds of a block.
+func (w *mdWriter) writeInlines(b *Block) {
+
+	w.start = w.buf.Len()
+
+	for _, o := range b.Inlines {
+		w.setMarks(w.marks(o))
+		if o.Type == "text" && o.HasAttr("code") && w.r.formats.Lookup("code") != nil {
+			w.writeCodeSpan(o.Data)
+		} else if o.Type == "text" {
+			w.writeText(o.Data)
+		} else {
+			w.writeEmbed(o)
+		}
+	}
+
+	w.setMarks(nil)
+
+}
+
+// marks lists the inline marks of the Op in nesting order.
+func (w *mdWriter) marks(o *Op) []mdMark {
+
+	r := w.r
+	html := r.mdFallback == HTMLUnsupported
+
+	var marks []mdMark
+	for attr, v := range o.Attrs {
+		if v == "" || r.formats.Lookup(attr) == nil {
+			continue
+		}
+		switch attr {
+		case "link":
+			if u, ok := r.urls.Resolve(v, LinkURL); ok {
+				marks = append(marks, mdMark{0, "link:" + v, "[", "](" + mdDestination(u) + ")"})
+			}
+		case "bold":
+			marks = append(marks, mdMark{6, attr, "**", "**"})
+		case "italic":
+			marks = append(marks, mdMark{7, attr, "*", "*"})
+		case "strike":
+			marks = append(marks, mdMark{8, attr, "~~", "~~"})
+		}
+		if !html {
+			continue
+		}
+		switch attr {
+		case "color", "background":
+			if css, ok := r.colors.Color(v); ok {
+				prop := "color:"
+				if attr == "background" {
+					prop = "background-color:"
+				}
+				rank := 2
+				if attr == "background" {
+					rank = 1
+				}
+				marks = append(marks, mdMark{rank, attr + ":" + css, `<span style="` + prop + css + `;">`, "</span>"})
+			}
+		case "size":
+			sizes := sizeStyles
+			if w.r.styles != nil {
+				sizes = w.r.styles.Sizes
+			}
+			if size, ok := sizes[v]; ok {
+				marks = append(marks, mdMark{3, attr + ":" + v, `<span style="font-size:` + size + `;">`, "</span>"})
+			}
+		case "underline":
+			marks = append(marks, mdMark{4, attr, "<u>", "</u>"})
+		case "script":
+			t := "sub"
+			if v == "super" {
+				t = "sup"
+			}
+			marks = append(marks, mdMark{5, attr + ":" + t, "<" + t + ">", "</" + t + ">"})
+		}
+	}
+
+	// Sort by rank (the list is always short).
+	for i := 1; i < len(marks); i++ {
+		for j := i; j > 0 && marks[j].rank < marks[j-1].rank; j-- {
+			marks[j], marks[j-1] = marks[j-1], marks[j]
+		}
+	}
+
+	return marks
+
+}
+
+// setMarks closes the open marks that are not in marks (along with any marks opened after them) and sets the rest of
+// marks to be opened.
+func (w *mdWriter) setMarks(marks []mdMark) {
+
+	all := make([]mdMark, 0, len(w.open)+len(w.pending))
+	all = append(append(all, w.open...), w.pending...)
+
+	keep := 0
+	for keep < len(all) && keep < len(marks) && all[keep].key == marks[keep].key {
+		keep++
+	}
+
+	// Marks that were never opened are simply forgotten.
+	if keep >= len(w.open) {
+		w.pending = append(w.pending[:0], all[len(w.open):keep]...)
+	} else {
+		w.pending = w.pending[:0]
+		w.closeMarks(keep)
+	}
+
+	w.pending = append(w.pending, marks[keep:]...)
+
+}
+
+// closeMarks closes the open marks down to the given number left open. Whitespace at the end of the text is moved out
+// of the marks so that the closing emphasis delimiters are valid.
+func (w *mdWriter) closeMarks(left int) {
+
+	b := w.buf.Bytes()
+	end := len(b)
+	for end > w.start && (b[end-1] == ' ' || b[end-1] == '\t') {
+		end--
+	}
+	space := string(b[end:])
+	w.buf.Truncate(end)
+
+	for i := len(w.open) - 1; i >= left; i-- {
+		w.buf.WriteString(w.open[i].close)
+	}
+	w.open = w.open[:left]
+
+	w.buf.WriteString(space)
+
+}
+
+// writeText writes escaped text, opening any pending marks right before the first character that is not whitespace.
+func (w *mdWriter) writeText(s string) {
+
+	trimmed := strings.TrimLeft(s, " \t")
+	if w.buf.Len() == w.start {
+		s = trimmed // Leading whitespace would turn a line into a code block.
+	} else {
+		w.buf.WriteString(s[:len(s)-len(trimmed)])
+	}
+	if trimmed == "" {
+		return
+	}
+
+	lineStart := w.buf.Len() == w.start && len(w.pending) == 0
+	w.flushMarks()
+	mdEscape(&w.buf, trimmed, lineStart)
+
+}
+
+// writeCodeSpan writes inline code as a code span, in which nothing is escaped.
+func (w *mdWriter) writeCodeSpan(s string) {
+
+	if s == "" {
+		return
+	}
+
+	fence := backtickFence(s, 1)
+
+	// A space is stripped from each end of the code, so a space is added where the code would otherwise lose one or where
+	// a backtick at an end would run into the fence.
+	pad := ""
+	if s[0] == '`' || s[len(s)-1] == '`' || (s[0] == ' ' && s[len(s)-1] == ' ' && strings.Trim(s, " ") != "") {
+		pad = " "
+	}
+
+	w.flushMarks()
+	w.buf.WriteString(fence + pad + s + pad + fence)
+
+}
+
+// flushMarks opens the pending marks.
+func (w *mdWriter) flushMarks() {
+	for _, m := range w.pending {
+		if b := w.buf.Bytes(); m.open == "[" && len(b) > w.start && b[len(b)-1] == '!' {
+			w.buf.Truncate(len(b) - 1)
+			w.buf.WriteString(`\!`) // A "!" right before a link would make it an image.
+		}
+		w.buf.WriteString(m.open)
+	}
+	w.open = append(w.open, w.pending...)
+	w.pending = w.pending[:0]
+}
+
+// writeEmbed writes an embed: images as Markdown images, and other embeds as HTML if that is allowed.
+func (w *mdWriter) writeEmbed(o *Op) {
+
+	if w.r.formats.Lookup(o.Type) == nil {
+		return
+	}
+
+	if o.Type == "image" {
+		src, ok := w.r.urls.Resolve(o.Data, ImageURL)
+		if !ok {
+			if src = w.r.urls.ImagePlaceholder; src == "" {
+				return
+			}
+		}
+		w.flushMarks()
+		w.buf.WriteString("![")
+		if alt := o.Attrs["alt"]; alt != "" {
+			mdEscape(&w.buf, alt, false)
+		}
+		w.buf.WriteString("](" + mdDestination(src) + ")")
+		return
+	}
+
+	if w.r.mdFallback != HTMLUnsupported {
+		return
+	}
+
+	if wr, ok := o.getFormatter(o.Type, w.r).(FormatWriter); ok {
+		w.flushMarks()
+		wr.Write(&w.buf)
+	}
+
+}
+
+// mdDestination formats a URL as the destination of a link or image.
+func mdDestination(u string) string {
+	if !strings.ContainsAny(u, " ()<>\\") {
+		return u
+	}
+	return "<" + strings.NewReplacer("<", `\<`, ">", `\>`, "\\", `\\`).Replace(u) + ">"
+}
+
+// mdEscape writes text to buf, escaping the characters that Markdown would otherwise take as markup. If lineStart is
+// true, the text begins a line, so characters that have a meaning only at the start of a line are escaped as well.
+func mdEscape(buf *bytes.Buffer, s string, lineStart bool) {
+
+	if lineStart {
+		switch s[0] {
+		case '#', '>', '-', '+', '=':
+			buf.WriteByte('\\')
+		default:
+			// Escape what would be taken as the marker of an ordered list item.
+			i := 0
+			for i < len(s) && i < 9 && s[i] >= '0' && s[i] <= '9' {
+				i++
+			}
+			if i > 0 && i < len(s) && (s[i] == '.' || s[i] == ')') {
+				buf.WriteString(s[:i])
+				buf.WriteByte('\\')
+				s = s[i:]
+			}
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\', '`', '*', '_', '[', ']', '<', '~', '|':
+			buf.WriteByte('\\')
+		case '!':
+			if i+1 < len(s) && s[i+1] == '[' {
+				buf.WriteByte('\\')
+			}
+		case '&':
+			// Escape only what could be taken as an entity.
+			if i+1 < len(s) && (s[i+1] == '#' || (s[i+1]|0x20 >= 'a' && s[i+1]|0x20 <= 'z')) {
+				buf.WriteByte('\\')
+			}
+		}
+		buf.WriteByte(s[i])
+	}
+
+}
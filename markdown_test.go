@@ -0,0 +1,147 @@
+package quill
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+
+	for _, n := range []string{"ops1", "list3", "list4", "code2"} {
+		t.Run(n, func(t *testing.T) {
+
+			ops, err := ioutil.ReadFile("./testdata/" + n + ".json")
+			if err != nil {
+				t.Fatalf("could not read %s.json; %s", n, err)
+			}
+
+			md, err := ioutil.ReadFile("./testdata/" + n + ".md")
+			if err != nil {
+				t.Fatalf("could not read %s.md; %s", n, err)
+			}
+
+			got, err := RenderMarkdown(ops)
+			if err != nil {
+				t.Errorf("error rendering; %v", err)
+			}
+
+			if !bytes.Equal(md, got) {
+				t.Errorf("bad rendering %s:\nwanted: \n%s\ngot: \n%s", n, md, got)
+			}
+
+		})
+	}
+
+}
+
+func TestRenderMarkdown_formats(t *testing.T) {
+
+	html := NewRenderer(WithMarkdownFallback(HTMLUnsupported))
+
+	cases := map[string]struct {
+		r    *Renderer
+		ops  string
+		want string
+	}{
+		"empty": {
+			ops:  `[{"insert":"\n"}]`,
+			want: "",
+		},
+		"blank lines dropped": {
+			ops:  `[{"insert":"a\n\n\nb\n"}]`,
+			want: "a\n\nb\n",
+		},
+		"nested emphasis": {
+			ops:  `[{"insert":"a "},{"insert":"b ","attributes":{"bold":true}},{"insert":"c","attributes":{"bold":true,"italic":true}},{"insert":" d\n"}]`,
+			want: "a **b *c*** d\n",
+		},
+		"whitespace outside emphasis": {
+			ops:  `[{"insert":" x ","attributes":{"italic":true}},{"insert":"y"},{"insert":"  ","attributes":{"bold":true}},{"insert":"\n"}]`,
+			want: "*x* y  \n",
+		},
+		"strike": {
+			ops:  `[{"insert":"gone","attributes":{"strike":true}},{"insert":"\n"}]`,
+			want: "~~gone~~\n",
+		},
+		"escaping": {
+			ops:  `[{"insert":"# not a heading * x_y & &amp; <b>\n1. not a list\n- nor this\n"}]`,
+			want: "\\# not a heading \\* x\\_y & \\&amp; \\<b>\n\n1\\. not a list\n\n\\- nor this\n",
+		},
+		"header levels": {
+			ops:  `[{"insert":"h3"},{"insert":"\n","attributes":{"header":3}},{"insert":"h9"},{"insert":"\n","attributes":{"header":9}}]`,
+			want: "### h3\n\n###### h9\n",
+		},
+		"link and image": {
+			ops: `[{"insert":"site","attributes":{"link":"https://example.com/a b"}},{"insert":" "},
+				{"insert":{"image":"https://example.com/i.png"}},{"insert":"bad","attributes":{"link":"javascript:x"}},{"insert":"\n"}]`,
+			want: "[site](<https://example.com/a b>) ![](https://example.com/i.png)bad\n",
+		},
+		"exclamation before a link": {
+			ops:  `[{"insert":"Wow!"},{"insert":"here","attributes":{"link":"https://x.com"}},{"insert":" and ![not] an image\n"}]`,
+			want: "Wow\\![here](https://x.com) and \\!\\[not\\] an image\n",
+		},
+		"image with alt text": {
+			ops:  `[{"insert":{"image":{"src":"i.png","alt":"a [b]"}}},{"insert":"\n"}]`,
+			want: "![a \\[b\\]](i.png)\n",
+		},
+		"task list": {
+			ops:  `[{"insert":"done"},{"insert":"\n","attributes":{"list":"checked"}},{"insert":"todo"},{"insert":"\n","attributes":{"list":"unchecked"}}]`,
+			want: "- [x] done\n- [ ] todo\n",
+		},
+		"inline code": {
+			ops: "[{\"insert\":\"run \"},{\"insert\":\"a*b_c\",\"attributes\":{\"code\":true}},{\"insert\":\", \"}," +
+				"{\"insert\":\"x `y` z\",\"attributes\":{\"code\":true,\"bold\":true}},{\"insert\":\" and \"}," +
+				"{\"insert\":\"`q``\",\"attributes\":{\"code\":true}},{\"insert\":\"\\n\"}]",
+			want: "run `a*b_c`, **``x `y` z``** and ``` `q`` ```\n",
+		},
+		"code with language and fences": {
+			ops:  "[{\"insert\":\"x := `a```b`\"},{\"insert\":\"\\n\",\"attributes\":{\"code-block\":\"go\"}}]",
+			want: "````go\nx := `a```b`\n````\n",
+		},
+		"unsupported dropped": {
+			ops:  `[{"insert":"u","attributes":{"underline":true,"color":"red","size":"huge","script":"super"}},{"insert":"\n","attributes":{"align":"center"}}]`,
+			want: "u\n",
+		},
+		"unsupported as html": {
+			r: html,
+			ops: `[{"insert":"u","attributes":{"underline":true,"color":"red","size":"huge","script":"super","bold":true}},
+				{"insert":"\n","attributes":{"align":"center"}}]`,
+			want: "<div align=\"center\">\n\n<span style=\"color:#ff0000;\"><span style=\"font-size:2.5em;\"><u><sup>**u**</sup></u></span></span>\n\n</div>\n",
+		},
+		"divider and page break": {
+			ops:  `[{"insert":"a\n"},{"insert":{"divider":true}},{"insert":"\n"},{"insert":{"page-break":true}},{"insert":"\nb\n"}]`,
+			want: "a\n\n---\n\nb\n",
+		},
+		"divider sharing a line": {
+			ops:  `[{"insert":{"divider":true}},{"insert":"hi\n"}]`,
+			want: "---\n\nhi\n",
+		},
+		"dividers sharing a line": {
+			ops:  `[{"insert":"a","attributes":{"bold":true}},{"insert":{"divider":true}},{"insert":{"divider":true}},{"insert":"hi\n","attributes":{"italic":true}}]`,
+			want: "**a**\n\n---\n\n---\n\n*hi*\n",
+		},
+		"disabled format": {
+			r:    NewRenderer(WithoutFormats("bold")),
+			ops:  `[{"insert":"b","attributes":{"bold":true,"italic":true}},{"insert":"\n"}]`,
+			want: "*b*\n",
+		},
+	}
+
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			r := tc.r
+			if r == nil {
+				r = defaultRenderer
+			}
+			got, err := r.RenderMarkdown([]byte(tc.ops))
+			if err != nil {
+				t.Fatalf("%s", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("bad rendering; got: %q", got)
+			}
+		})
+	}
+
+}
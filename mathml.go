@@ -0,0 +1,743 @@
+package quill
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// errUnsupportedTeX is returned by texToMathML for LaTeX that is not in the supported subset.
+var errUnsupportedTeX = errors.New("quill: unsupported LaTeX in formula")
+
+// maxTeXDepth limits how deeply the groups of a formula may be nested.
+const maxTeXDepth = 50
+
+// texToMathML converts a formula written in a subset of LaTeX to a MathML math element, which keeps the source in an
+// annotation. The subset covers what is commonly typed into Quill's formula editor: numbers, letters and operators;
+// sub- and superscripts; fractions, binomials and roots; Greek letters and the usual symbols; sums, products, integrals
+// and limits; function names; \left and \right delimiters; \text and font commands; accents; and the matrix and cases
+// environments. Anything else gives errUnsupportedTeX.
+func texToMathML(tex string) (string, error) {
+
+	p := texParser{src: tex}
+	nodes, err := p.parseList()
+	if err != nil {
+		return "", err
+	}
+	if p.pos < len(p.src) || len(nodes) == 0 {
+		return "", errUnsupportedTeX // a "}", "&", "\\", \right or \end without its opening, or nothing at all
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<math xmlns="http://www.w3.org/1998/Math/MathML"><semantics><mrow>`)
+	for _, n := range nodes {
+		sb.WriteString(n)
+	}
+	sb.WriteString(`</mrow><annotation encoding="application/x-tex">`)
+	textEscaper.WriteString(&sb, tex)
+	sb.WriteString(`</annotation></semantics></math>`)
+
+	return sb.String(), nil
+
+}
+
+// The kinds of atoms, which say how their scripts are placed.
+const (
+	plainAtom    = iota
+	limitsAtom   // a large operator or limit, with its scripts below and above it
+	functionAtom // the name of a function, which is followed by an invisible function application
+)
+
+// A texParser reads LaTeX and writes MathML.
+type texParser struct {
+	src   string
+	pos   int
+	depth int
+}
+
+// parseList parses atoms with their scripts up to the end of the source or to a "}", "&", "\\", \right or \end, which is
+// left for the caller to read. Each atom is returned as a single MathML element.
+func (p *texParser) parseList() ([]string, error) {
+
+	if p.depth++; p.depth > maxTeXDepth {
+		return nil, errUnsupportedTeX
+	}
+	defer func() { p.depth-- }()
+
+	var nodes []string
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nodes, nil
+		}
+		switch c := p.src[p.pos]; c {
+		case '}', '&':
+			return nodes, nil
+		case '\\':
+			switch p.peekCommand() {
+			case "\\", "right", "end":
+				return nodes, nil
+			}
+		}
+		node, err := p.parseScripted()
+		if err != nil {
+			return nil, err
+		}
+		if node != "" {
+			nodes = append(nodes, node)
+		}
+	}
+
+}
+
+// parseScripted parses an atom along with its subscript, superscript, and primes.
+func (p *texParser) parseScripted() (string, error) {
+
+	base, kind, err := p.parseAtom()
+	if err != nil {
+		return "", err
+	}
+
+	var sub, sup, primes string
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			break
+		}
+		c := p.src[p.pos]
+		if c == '\'' {
+			p.pos++
+			primes += "′"
+			continue
+		}
+		if cmd := p.peekCommand(); kind == limitsAtom && (cmd == "limits" || cmd == "nolimits") {
+			p.readCommand()
+			continue
+		}
+		if c != '^' && c != '_' {
+			break
+		}
+		p.pos++
+		arg, err := p.parseArg()
+		if err != nil {
+			return "", err
+		}
+		if c == '^' {
+			if sup != "" {
+				return "", errUnsupportedTeX // a double superscript
+			}
+			sup = arg
+		} else {
+			if sub != "" {
+				return "", errUnsupportedTeX // a double subscript
+			}
+			sub = arg
+		}
+	}
+	if primes != "" {
+		if sup != "" {
+			sup = "<mrow><mo>" + primes + "</mo>" + sup + "</mrow>"
+		} else {
+			sup = "<mo>" + primes + "</mo>"
+		}
+	}
+
+	node := base
+	if sub != "" || sup != "" {
+		if base == "" {
+			base = "<mrow></mrow>"
+		}
+		tags := [3]string{"msub", "msup", "msubsup"}
+		if kind == limitsAtom {
+			tags = [3]string{"munder", "mover", "munderover"}
+		}
+		switch {
+		case sup == "":
+			node = "<" + tags[0] + ">" + base + sub + "</" + tags[0] + ">"
+		case sub == "":
+			node = "<" + tags[1] + ">" + base + sup + "</" + tags[1] + ">"
+		default:
+			node = "<" + tags[2] + ">" + base + sub + sup + "</" + tags[2] + ">"
+		}
+	}
+	if kind == functionAtom {
+		node = "<mrow>" + node + "<mo>⁡</mo></mrow>"
+	}
+
+	return node, nil
+
+}
+
+// parseArg parses the argument of a command or script: a group in braces or else a single character or command.
+func (p *texParser) parseArg() (string, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return "", errUnsupportedTeX
+	}
+	switch c := p.src[p.pos]; {
+	case c == '{':
+		return p.parseGroup()
+	case isDigit(c):
+		p.pos++
+		return "<mn>" + string(c) + "</mn>", nil
+	case c == '}' || c == '&' || c == '^' || c == '_':
+		return "", errUnsupportedTeX
+	}
+	node, _, err := p.parseAtom()
+	if err == nil && node == "" {
+		err = errUnsupportedTeX
+	}
+	return node, err
+}
+
+// parseGroup parses a group in braces as a single element.
+func (p *texParser) parseGroup() (string, error) {
+	p.pos++ // the "{"
+	nodes, err := p.parseList()
+	if err != nil {
+		return "", err
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != '}' {
+		return "", errUnsupportedTeX
+	}
+	p.pos++
+	return mrow(nodes), nil
+}
+
+// parseAtom parses a number, a letter, an operator, a group or a command. Commands that only change the style of what
+// follows give a blank node.
+func (p *texParser) parseAtom() (string, int, error) {
+
+	c := p.src[p.pos]
+
+	switch {
+	case c == '{':
+		g, err := p.parseGroup()
+		return g, plainAtom, err
+	case c == '\\':
+		return p.parseCommand()
+	case isDigit(c) || c == '.' && p.pos+1 < len(p.src) && isDigit(p.src[p.pos+1]):
+		i := p.pos
+		for i < len(p.src) && (isDigit(p.src[i]) || p.src[i] == '.' && i+1 < len(p.src) && isDigit(p.src[i+1])) {
+			i++
+		}
+		n := p.src[p.pos:i]
+		p.pos = i
+		return "<mn>" + n + "</mn>", plainAtom, nil
+	case isLetter(c):
+		p.pos++
+		return "<mi>" + string(c) + "</mi>", plainAtom, nil
+	case c == '~':
+		p.pos++
+		return `<mspace width="0.3333em"></mspace>`, plainAtom, nil
+	case c >= utf8.RuneSelf:
+		r, n := utf8.DecodeRuneInString(p.src[p.pos:])
+		p.pos += n
+		if unicode.IsLetter(r) {
+			return mi(string(r)), plainAtom, nil
+		}
+		return mo(string(r)), plainAtom, nil
+	}
+
+	if op, ok := texOperatorChars[c]; ok {
+		p.pos++
+		return mo(op), plainAtom, nil
+	}
+	return "", plainAtom, errUnsupportedTeX
+
+}
+
+// parseCommand parses a command and its arguments.
+func (p *texParser) parseCommand() (string, int, error) {
+
+	name := p.readCommand()
+
+	if s, ok := texIdentifiers[name]; ok {
+		return mi(s), plainAtom, nil
+	}
+	if s, ok := texUprightIdentifiers[name]; ok {
+		return `<mi mathvariant="normal">` + s + "</mi>", plainAtom, nil
+	}
+	if s, ok := texOperators[name]; ok {
+		return mo(s), plainAtom, nil
+	}
+	if s, ok := texLargeOperators[name]; ok {
+		return mo(s), limitsAtom, nil
+	}
+	if s, ok := texIntegrals[name]; ok {
+		return mo(s), plainAtom, nil
+	}
+	if limits, ok := texFunctions[name]; ok {
+		if limits {
+			return mi(name), limitsAtom, nil
+		}
+		return mi(name), functionAtom, nil
+	}
+	if w, ok := texSpaces[name]; ok {
+		return `<mspace width="` + w + `"></mspace>`, plainAtom, nil
+	}
+	if v, ok := texFonts[name]; ok {
+		return p.parseFont(v)
+	}
+	if a, ok := texAccents[name]; ok {
+		arg, err := p.parseArg()
+		if err != nil {
+			return "", plainAtom, err
+		}
+		if name == "underline" {
+			return `<munder accentunder="true">` + arg + mo(a) + "</munder>", plainAtom, nil
+		}
+		return `<mover accent="true">` + arg + mo(a) + "</mover>", plainAtom, nil
+	}
+	if size, ok := texBigSizes[strings.TrimRight(name, "lrm")]; ok && len(name)-len(strings.TrimRight(name, "lrm")) <= 1 {
+		d, err := p.readDelim()
+		if err != nil || d == "" {
+			return "", plainAtom, errUnsupportedTeX
+		}
+		return `<mo minsize="` + size + `" maxsize="` + size + `">` + textEscaper.Replace(d) + "</mo>", plainAtom, nil
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "cfrac", "binom":
+		num, err := p.parseArg()
+		if err != nil {
+			return "", plainAtom, err
+		}
+		den, err := p.parseArg()
+		if err != nil {
+			return "", plainAtom, err
+		}
+		if name == "binom" {
+			return `<mrow><mo>(</mo><mfrac linethickness="0">` + num + den + `</mfrac><mo>)</mo></mrow>`, plainAtom, nil
+		}
+		return "<mfrac>" + num + den + "</mfrac>", plainAtom, nil
+	case "sqrt":
+		p.skipSpace()
+		index := ""
+		if p.pos < len(p.src) && p.src[p.pos] == '[' {
+			inner, err := p.readBracketed()
+			if err != nil {
+				return "", plainAtom, err
+			}
+			if index, err = p.parseSub(inner); err != nil {
+				return "", plainAtom, err
+			}
+		}
+		arg, err := p.parseArg()
+		if err != nil {
+			return "", plainAtom, err
+		}
+		if index != "" {
+			return "<mroot>" + arg + index + "</mroot>", plainAtom, nil
+		}
+		return "<msqrt>" + arg + "</msqrt>", plainAtom, nil
+	case "left":
+		return p.parseLeftRight()
+	case "middle":
+		d, err := p.readDelim()
+		if err != nil {
+			return "", plainAtom, err
+		}
+		return `<mo fence="true" stretchy="true">` + textEscaper.Replace(d) + "</mo>", plainAtom, nil
+	case "text", "textrm", "textnormal", "mbox", "textit", "textbf":
+		text, err := p.readBraced()
+		if err != nil {
+			return "", plainAtom, err
+		}
+		variant := ""
+		switch name {
+		case "textit":
+			variant = ` mathvariant="italic"`
+		case "textbf":
+			variant = ` mathvariant="bold"`
+		}
+		// White space at the ends of an mtext element would be dropped, so it is kept as a no-break space.
+		if trimmed := strings.TrimLeft(text, " "); len(trimmed) < len(text) {
+			text = "\u00a0" + trimmed
+		}
+		if trimmed := strings.TrimRight(text, " "); len(trimmed) < len(text) {
+			text = trimmed + "\u00a0"
+		}
+		return "<mtext" + variant + ">" + textEscaper.Replace(text) + "</mtext>", plainAtom, nil
+	case "begin":
+		return p.parseEnvironment()
+	case "displaystyle", "textstyle", "scriptstyle":
+		return "", plainAtom, nil
+	}
+
+	return "", plainAtom, errUnsupportedTeX
+
+}
+
+// parseFont parses the argument of a font command, which may hold only letters, digits and spaces.
+func (p *texParser) parseFont(variant string) (string, int, error) {
+	text, err := p.readBraced()
+	if err != nil {
+		return "", plainAtom, err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", plainAtom, errUnsupportedTeX
+	}
+	for i := 0; i < len(text); i++ {
+		if !isLetter(text[i]) && !isDigit(text[i]) && text[i] != ' ' {
+			return "", plainAtom, errUnsupportedTeX
+		}
+	}
+	if strings.Trim(text, "0123456789") == "" {
+		return `<mn mathvariant="` + variant + `">` + text + "</mn>", plainAtom, nil
+	}
+	return `<mi mathvariant="` + variant + `">` + text + "</mi>", plainAtom, nil
+}
+
+// parseLeftRight parses what follows \left up to and including the matching \right.
+func (p *texParser) parseLeftRight() (string, int, error) {
+
+	open, err := p.readDelim()
+	if err != nil {
+		return "", plainAtom, err
+	}
+	nodes, err := p.parseList()
+	if err != nil {
+		return "", plainAtom, err
+	}
+	if p.peekCommand() != "right" {
+		return "", plainAtom, errUnsupportedTeX
+	}
+	p.readCommand()
+	close, err := p.readDelim()
+	if err != nil {
+		return "", plainAtom, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<mrow>")
+	if open != "" {
+		sb.WriteString(`<mo fence="true" stretchy="true">` + textEscaper.Replace(open) + "</mo>")
+	}
+	for _, n := range nodes {
+		sb.WriteString(n)
+	}
+	if close != "" {
+		sb.WriteString(`<mo fence="true" stretchy="true">` + textEscaper.Replace(close) + "</mo>")
+	}
+	sb.WriteString("</mrow>")
+
+	return sb.String(), plainAtom, nil
+
+}
+
+// A texEnvironment gives how the table of a matrix or cases environment is written.
+type texEnvironment struct {
+	open, close string // the delimiters around the table
+	attrs       string // the attributes of the mtable element
+}
+
+// texEnvironments lists the supported environments.
+var texEnvironments = map[string]texEnvironment{
+	"matrix":      {},
+	"smallmatrix": {attrs: ` displaystyle="false"`},
+	"pmatrix":     {open: "(", close: ")"},
+	"bmatrix":     {open: "[", close: "]"},
+	"Bmatrix":     {open: "{", close: "}"},
+	"vmatrix":     {open: "|", close: "|"},
+	"Vmatrix":     {open: "‖", close: "‖"},
+	"cases":       {open: "{", attrs: ` columnalign="left left"`},
+	"aligned":     {attrs: ` columnalign="right left"`},
+}
+
+// parseEnvironment parses what follows \begin up to and including the matching \end.
+func (p *texParser) parseEnvironment() (string, int, error) {
+
+	name, err := p.readBraced()
+	if err != nil {
+		return "", plainAtom, err
+	}
+	env, ok := texEnvironments[name]
+	if !ok {
+		return "", plainAtom, errUnsupportedTeX
+	}
+
+	var rows [][]string
+	var row []string
+	for done := false; !done; {
+		nodes, err := p.parseList()
+		if err != nil {
+			return "", plainAtom, err
+		}
+		row = append(row, strings.Join(nodes, ""))
+		if p.pos >= len(p.src) {
+			return "", plainAtom, errUnsupportedTeX
+		}
+		if p.src[p.pos] == '&' {
+			p.pos++
+			continue
+		}
+		switch p.peekCommand() {
+		case "\\":
+			p.readCommand()
+		case "end":
+			p.readCommand()
+			if end, err := p.readBraced(); err != nil || end != name {
+				return "", plainAtom, errUnsupportedTeX
+			}
+			done = true
+		default:
+			return "", plainAtom, errUnsupportedTeX
+		}
+		rows = append(rows, row)
+		row = nil
+	}
+	// A "\\" at the end of the last row does not start another.
+	if n := len(rows); n > 1 && len(rows[n-1]) == 1 && rows[n-1][0] == "" {
+		rows = rows[:n-1]
+	}
+
+	var sb strings.Builder
+	if env.open != "" || env.close != "" {
+		sb.WriteString("<mrow>")
+	}
+	if env.open != "" {
+		sb.WriteString(`<mo fence="true" stretchy="true">` + env.open + "</mo>")
+	}
+	sb.WriteString("<mtable" + env.attrs + ">")
+	for _, r := range rows {
+		sb.WriteString("<mtr>")
+		for _, cell := range r {
+			sb.WriteString("<mtd>" + cell + "</mtd>")
+		}
+		sb.WriteString("</mtr>")
+	}
+	sb.WriteString("</mtable>")
+	if env.close != "" {
+		sb.WriteString(`<mo fence="true" stretchy="true">` + env.close + "</mo>")
+	}
+	if env.open != "" || env.close != "" {
+		sb.WriteString("</mrow>")
+	}
+
+	return sb.String(), plainAtom, nil
+
+}
+
+// parseSub parses a part of the source, such as the index of a root, that was read on its own.
+func (p *texParser) parseSub(src string) (string, error) {
+	sub := texParser{src: src, depth: p.depth}
+	nodes, err := sub.parseList()
+	if err != nil {
+		return "", err
+	}
+	if sub.pos < len(sub.src) {
+		return "", errUnsupportedTeX
+	}
+	return mrow(nodes), nil
+}
+
+// skipSpace skips white space, which has no meaning in math.
+func (p *texParser) skipSpace() {
+	for p.pos < len(p.src) && isSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// peekCommand returns the name of the command at the current position without reading it, or "" if there is none. The
+// name is either a run of letters or a single other character.
+func (p *texParser) peekCommand() string {
+	i := p.pos + 1
+	if p.pos >= len(p.src) || p.src[p.pos] != '\\' || i >= len(p.src) {
+		return ""
+	}
+	if !isLetter(p.src[i]) {
+		return p.src[i : i+1]
+	}
+	j := i
+	for j < len(p.src) && isLetter(p.src[j]) {
+		j++
+	}
+	return p.src[i:j]
+}
+
+// readCommand reads the command at the current position and returns its name.
+func (p *texParser) readCommand() string {
+	name := p.peekCommand()
+	p.pos += 1 + len(name)
+	return name
+}
+
+// readBraced reads a group in braces and returns its source.
+func (p *texParser) readBraced() (string, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) || p.src[p.pos] != '{' {
+		return "", errUnsupportedTeX
+	}
+	depth := 0
+	for i := p.pos; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '\\':
+			i++ // An escaped brace does not count.
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				s := p.src[p.pos+1 : i]
+				p.pos = i + 1
+				return s, nil
+			}
+		}
+	}
+	return "", errUnsupportedTeX
+}
+
+// readBracketed reads an optional argument in brackets and returns its source.
+func (p *texParser) readBracketed() (string, error) {
+	depth := 0
+	for i := p.pos + 1; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ']':
+			if depth == 0 {
+				s := p.src[p.pos+1 : i]
+				p.pos = i + 1
+				return s, nil
+			}
+		}
+	}
+	return "", errUnsupportedTeX
+}
+
+// readDelim reads the delimiter after \left, \right, \middle or \big. The blank delimiter "." gives "".
+func (p *texParser) readDelim() (string, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return "", errUnsupportedTeX
+	}
+	c := p.src[p.pos]
+	if c == '\\' {
+		if d, ok := texDelimiters[p.readCommand()]; ok {
+			return d, nil
+		}
+		return "", errUnsupportedTeX
+	}
+	p.pos++
+	switch c {
+	case '.':
+		return "", nil
+	case '<':
+		return "⟨", nil
+	case '>':
+		return "⟩", nil
+	case '(', ')', '[', ']', '|', '/':
+		return string(c), nil
+	}
+	return "", errUnsupportedTeX
+}
+
+// mrow returns the elements as a single element.
+func mrow(nodes []string) string {
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+	return "<mrow>" + strings.Join(nodes, "") + "</mrow>"
+}
+
+func mi(s string) string {
+	return "<mi>" + textEscaper.Replace(s) + "</mi>"
+}
+
+func mo(s string) string {
+	return "<mo>" + textEscaper.Replace(s) + "</mo>"
+}
+
+// texOperatorChars gives the operators written as single characters.
+var texOperatorChars = map[byte]string{
+	'+': "+", '-': "−", '=': "=", '<': "<", '>': ">", ',': ",", ';': ";", ':': ":", '!': "!", '?': "?", '/': "/",
+	'*': "∗", '|': "|", '(': "(", ')': ")", '[': "[", ']': "]", '.': ".", '\'': "′", '@': "@",
+}
+
+// texIdentifiers gives the lower-case Greek letters and other symbols that are identifiers.
+var texIdentifiers = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ϵ", "varepsilon": "ε", "zeta": "ζ", "eta": "η",
+	"theta": "θ", "vartheta": "ϑ", "iota": "ι", "kappa": "κ", "lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ",
+	"omicron": "ο", "pi": "π", "varpi": "ϖ", "rho": "ρ", "varrho": "ϱ", "sigma": "σ", "varsigma": "ς", "tau": "τ",
+	"upsilon": "υ", "phi": "ϕ", "varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"infty": "∞", "partial": "∂", "nabla": "∇", "emptyset": "∅", "varnothing": "∅", "hbar": "ℏ", "ell": "ℓ",
+	"Re": "ℜ", "Im": "ℑ", "aleph": "ℵ", "wp": "℘", "imath": "ı", "jmath": "ȷ",
+}
+
+// texUprightIdentifiers gives the upper-case Greek letters, which are upright.
+var texUprightIdentifiers = map[string]string{
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π", "Sigma": "Σ", "Upsilon": "Υ",
+	"Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+}
+
+// texOperators gives the symbols that are operators, relations, arrows, dots and delimiters.
+var texOperators = map[string]string{
+	"cdot": "⋅", "times": "×", "div": "÷", "pm": "±", "mp": "∓", "ast": "∗", "star": "⋆", "circ": "∘",
+	"bullet": "∙", "oplus": "⊕", "otimes": "⊗", "odot": "⊙", "setminus": "∖", "cup": "∪", "cap": "∩",
+	"wedge": "∧", "land": "∧", "vee": "∨", "lor": "∨", "neg": "¬", "lnot": "¬",
+	"leq": "≤", "le": "≤", "geq": "≥", "ge": "≥", "neq": "≠", "ne": "≠", "approx": "≈", "equiv": "≡", "sim": "∼",
+	"simeq": "≃", "cong": "≅", "propto": "∝", "ll": "≪", "gg": "≫", "in": "∈", "notin": "∉", "ni": "∋",
+	"subset": "⊂", "supset": "⊃", "subseteq": "⊆", "supseteq": "⊇", "mid": "∣", "parallel": "∥", "perp": "⊥",
+	"forall": "∀", "exists": "∃", "angle": "∠", "triangle": "△", "prime": "′", "colon": ":",
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "gets": "←", "leftrightarrow": "↔", "Rightarrow": "⇒",
+	"Leftarrow": "⇐", "Leftrightarrow": "⇔", "implies": "⟹", "impliedby": "⟸", "iff": "⟺", "mapsto": "↦",
+	"uparrow": "↑", "downarrow": "↓", "longrightarrow": "⟶", "longleftarrow": "⟵",
+	"ldots": "…", "dots": "…", "cdots": "⋯", "vdots": "⋮", "ddots": "⋱",
+	"langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈", "rceil": "⌉",
+	"{": "{", "}": "}", "|": "‖", "%": "%", "$": "$", "&": "&", "#": "#", "_": "_",
+}
+
+// texDelimiters gives the delimiters that are commands.
+var texDelimiters = map[string]string{
+	"{": "{", "}": "}", "|": "‖", "langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈",
+	"rceil": "⌉", "vert": "|", "lvert": "|", "rvert": "|", "Vert": "‖", "lVert": "‖", "rVert": "‖",
+}
+
+// texLargeOperators gives the large operators, which have limits below and above them.
+var texLargeOperators = map[string]string{
+	"sum": "∑", "prod": "∏", "coprod": "∐", "bigcup": "⋃", "bigcap": "⋂", "bigoplus": "⨁", "bigotimes": "⨂",
+}
+
+// texIntegrals gives the integrals, which have their limits as scripts.
+var texIntegrals = map[string]string{
+	"int": "∫", "iint": "∬", "iiint": "∭", "oint": "∮",
+}
+
+// texFunctions lists the names of functions and says which of them have limits below them.
+var texFunctions = map[string]bool{
+	"sin": false, "cos": false, "tan": false, "cot": false, "sec": false, "csc": false, "arcsin": false,
+	"arccos": false, "arctan": false, "sinh": false, "cosh": false, "tanh": false, "coth": false, "log": false,
+	"ln": false, "lg": false, "exp": false, "dim": false, "ker": false, "deg": false, "hom": false, "arg": false,
+	"lim": true, "max": true, "min": true, "sup": true, "inf": true, "det": true, "gcd": true, "Pr": true,
+}
+
+// texSpaces gives the widths of the spacing commands.
+var texSpaces = map[string]string{
+	",": "0.1667em", "thinspace": "0.1667em", ":": "0.2222em", ">": "0.2222em", "medspace": "0.2222em",
+	";": "0.2778em", "thickspace": "0.2778em", "!": "-0.1667em", " ": "0.3333em", "quad": "1em", "qquad": "2em",
+}
+
+// texFonts gives the mathvariant of each font command.
+var texFonts = map[string]string{
+	"mathrm": "normal", "operatorname": "normal", "mathbf": "bold", "mathit": "italic", "mathbb": "double-struck",
+	"mathcal": "script", "mathscr": "script", "mathsf": "sans-serif", "mathtt": "monospace", "mathfrak": "fraktur",
+	"boldsymbol": "bold-italic",
+}
+
+// texAccents gives the mark of each accent.
+var texAccents = map[string]string{
+	"hat": "^", "widehat": "^", "bar": "¯", "overline": "¯", "vec": "→", "overrightarrow": "→", "dot": "˙",
+	"ddot": "¨", "tilde": "~", "widetilde": "~", "check": "ˇ", "breve": "˘", "acute": "´", "grave": "`",
+	"underline": "_",
+}
+
+// texBigSizes gives the sizes of the delimiters of \big and its kin, which may end with l, r or m.
+var texBigSizes = map[string]string{
+	"big": "1.2em", "Big": "1.623em", "bigg": "2.047em", "Bigg": "2.470em",
+}
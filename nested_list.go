@@ -0,0 +1,99 @@
+package quill
+
+import "strconv"
+
+// WithNestedLists makes the Renderer write indented list items inside of the item before them, in a list of their own
+// (as in <ul><li>a<ul><li>b</li></ul></li></ul>), rather than as siblings with an indent-N class the way that Quill
+// writes them. Ordered and bullet lists can be mixed at any depth.
+func WithNestedLists() Option {
+	return func(r *Renderer) {
+		r.nestedLists = true
+	}
+}
+
+// A nestedListFormat is the format of a list item when lists are nested. Unlike a listFormat, it is not a
+// FormatWrapper: an item must stay open while the items nested in it are written, so the list and item tags around
+// it are written by the renderVars.
+type nestedListFormat struct {
+	lType  string // either "ul" or "ol"
+	list   string // the value of the list attribute, such as "bullet" or "checked"
+	indent int    // the depth of the item, starting from 0
+	attrs  string // the attributes of the li tag
+}
+
+// newNestedListFormat returns the format for the list item o. For Quill 2, checklist items are in a ul list and have
+// a data-list attribute.
+func newNestedListFormat(o *Op, r *Renderer) *nestedListFormat {
+	nl := &nestedListFormat{lType: "ol", list: o.Attrs["list"], indent: listDepth(o)}
+	switch t := o.Attrs["list"]; {
+	case t == "bullet":
+		nl.lType = "ul"
+	case r.version == Quill2 && (t == "checked" || t == "unchecked"):
+		nl.lType = "ul"
+		nl.attrs = ` data-list="` + t + `"`
+	}
+	return nl
+}
+
+// listDepth returns the depth of the list item o, which is its indent up to the eight levels that Quill allows.
+func listDepth(o *Op) int {
+	n, err := strconv.Atoi(o.Attrs["indent"])
+	switch {
+	case err != nil || n < 0:
+		return 0
+	case n > len(quillIndents):
+		return len(quillIndents)
+	}
+	return n
+}
+
+func (lf *nestedListFormat) Fmt() *Format {
+	return &Format{
+		Val:   "li",
+		Place: Tag,
+		Block: true,
+	}
+}
+
+func (lf *nestedListFormat) HasFormat(o *Op) bool {
+	return o.HasAttr("list")
+}
+
+// nestedList returns the format of the current block if it is a nested list item.
+func (vars *renderVars) nestedList() *nestedListFormat {
+	for _, fm := range vars.fms {
+		if nl, ok := fm.fm.(*nestedListFormat); ok {
+			return nl
+		}
+	}
+	return nil
+}
+
+// closeLists closes the list items and lists that do not go on to the next block, which is either the list item nl or,
+// if nl is nil, a block that is not a list item.
+func (vars *renderVars) closeLists(nl *nestedListFormat) {
+	for len(vars.lists) > 0 {
+		top := len(vars.lists) - 1
+		if nl != nil && top < nl.indent {
+			return // The item goes inside of the open item.
+		}
+		if nl != nil && top == nl.indent && vars.lists[top].list == nl.list {
+			vars.finalBuf.WriteString("</li>") // The item follows the open item in the same list.
+			return
+		}
+		vars.finalBuf.WriteString("</li></" + vars.lists[top].lType + ">")
+		vars.lists = vars.lists[:top]
+	}
+}
+
+// openLists opens the lists that the list item nl goes into. If the item is indented more than one level deeper than
+// the item before it, an item is opened for each level skipped over.
+func (vars *renderVars) openLists(nl *nestedListFormat) {
+	for len(vars.lists) <= nl.indent {
+		vars.finalBuf.WriteString("<" + nl.lType + ">")
+		vars.lists = append(vars.lists, nl)
+		if len(vars.lists) <= nl.indent {
+			vars.finalBuf.WriteString("<li>")
+		}
+	}
+}
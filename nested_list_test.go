@@ -0,0 +1,101 @@
+package quill
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+)
+
+func TestWithNestedLists(t *testing.T) {
+
+	r := NewRenderer(WithNestedLists())
+
+	cases := map[string]struct {
+		ops  string
+		want string
+	}{
+		"flat": {
+			ops:  `[{"insert":"a"},{"attributes":{"list":"bullet"},"insert":"\n"},{"insert":"b"},{"attributes":{"list":"bullet"},"insert":"\n"}]`,
+			want: "<ul><li>a</li><li>b</li></ul>",
+		},
+		"nested": {
+			ops: `[{"insert":"a"},{"attributes":{"list":"bullet"},"insert":"\n"},` +
+				`{"insert":"b"},{"attributes":{"list":"bullet","indent":1},"insert":"\n"},` +
+				`{"insert":"c"},{"attributes":{"list":"bullet"},"insert":"\n"}]`,
+			want: "<ul><li>a<ul><li>b</li></ul></li><li>c</li></ul>",
+		},
+		"mixed types": {
+			ops: `[{"insert":"a"},{"attributes":{"list":"ordered"},"insert":"\n"},` +
+				`{"insert":"b"},{"attributes":{"list":"bullet","indent":1},"insert":"\n"},` +
+				`{"insert":"c"},{"attributes":{"list":"ordered","indent":1},"insert":"\n"},` +
+				`{"insert":"d"},{"attributes":{"list":"bullet","indent":2},"insert":"\n"},` +
+				`{"insert":"e"},{"attributes":{"list":"bullet"},"insert":"\n"}]`,
+			want: "<ol><li>a<ul><li>b</li></ul><ol><li>c<ul><li>d</li></ul></li></ol></li></ol><ul><li>e</li></ul>",
+		},
+		"skipped level": {
+			ops:  `[{"insert":"a"},{"attributes":{"list":"bullet"},"insert":"\n"},{"insert":"b"},{"attributes":{"list":"bullet","indent":2},"insert":"\n"}]`,
+			want: "<ul><li>a<ul><li><ul><li>b</li></ul></li></ul></li></ul>",
+		},
+		"starts indented": {
+			ops:  `[{"insert":"a"},{"attributes":{"list":"ordered","indent":1},"insert":"\n"}]`,
+			want: "<ol><li><ol><li>a</li></ol></li></ol>",
+		},
+		"between paragraphs": {
+			ops: `[{"insert":"p\na"},{"attributes":{"list":"bullet"},"insert":"\n"},` +
+				`{"insert":"b"},{"attributes":{"list":"bullet","indent":1},"insert":"\n"},{"insert":"q\n"}]`,
+			want: "<p>p</p><ul><li>a<ul><li>b</li></ul></li></ul><p>q</p>",
+		},
+		"formats in items": {
+			ops: `[{"insert":"a "},{"attributes":{"bold":true},"insert":"b"},{"attributes":{"list":"bullet","align":"center"},"insert":"\n"},` +
+				`{"attributes":{"italic":true},"insert":"c"},{"attributes":{"list":"bullet","indent":1},"insert":"\n"}]`,
+			want: `<ul><li class="ql-align-center">a <strong>b</strong><ul><li><em>c</em></li></ul></li></ul>`,
+		},
+		"deep levels": {
+			ops: `[{"insert":"a"},{"attributes":{"list":"bullet","indent":6},"insert":"\n"},` +
+				`{"insert":"b"},{"attributes":{"list":"bullet","indent":7},"insert":"\n"},` +
+				`{"insert":"c"},{"attributes":{"list":"bullet","indent":12},"insert":"\n"}]`,
+			want: "<ul><li><ul><li><ul><li><ul><li><ul><li><ul><li><ul><li>a<ul><li>b<ul><li>c" +
+				"</li></ul></li></ul></li></ul></li></ul></li></ul></li></ul></li></ul></li></ul></li></ul>",
+		},
+		"indented paragraph": {
+			ops:  `[{"insert":"a"},{"attributes":{"indent":1},"insert":"\n"}]`,
+			want: `<p class="indent-1">a</p>`,
+		},
+	}
+
+	for name, c := range cases {
+		got, err := r.Render([]byte(c.ops))
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		if string(got) != c.want {
+			t.Errorf("%s: bad rendering\ngot:  %s\nwant: %s", name, got, c.want)
+		}
+	}
+
+	ops, err := ioutil.ReadFile("./testdata/list4.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "<ol><li>1(ol)-1</li><li>1(ol)-2 <strong>bold</strong></li></ol><ul><li>1(ul)-3 <em>italic</em></li></ul>" +
+		"<ol><li>1(ol)-4<ol><li><em><u>under-ital</u></em>_before 2(ol)-1<ol><li>3(ol)-1</li><li>3(ol)-2</li></ol>" +
+		"<ul><li>3(ul)-3</li></ul></li></ol><ul><li>2(ul)-2</li></ul></li></ol>"
+	got, err := r.Render(ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("bad rendering of list4\ngot:  %s\nwant: %s", got, want)
+	}
+
+	// Streaming gives the same output.
+	var buf bytes.Buffer
+	if err := r.RenderTo(&buf, bytes.NewReader(ops)); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != want {
+		t.Errorf("bad streamed rendering of list4: %s", buf.String())
+	}
+
+}
@@ -0,0 +1,35 @@
+package quill
+
+// A QuillVersion selects the version of Quill whose HTML the Renderer matches.
+type QuillVersion uint8
+
+const (
+	// Quill1 matches the HTML of the Quill 1 editor. This is the default.
+	Quill1 QuillVersion = iota
+
+	// Quill2 matches the HTML that Quill 2 gives with getSemanticHTML. Lists are nested, with ul and ol elements, and
+	// checklist items have a data-list attribute; indented blocks have a ql-indent-N class; code blocks are written
+	// with the language in a data-language attribute; and links have rel="noopener noreferrer". The "checked" and
+	// "unchecked" list values and code block languages, which Quill 2 puts in Deltas, are read.
+	Quill2
+)
+
+// WithQuillVersion sets the version of Quill whose HTML the Renderer matches.
+func WithQuillVersion(v QuillVersion) Option {
+	return func(r *Renderer) {
+		r.version = v
+	}
+}
+
+// nestsLists says if the Renderer writes indented list items nested inside of the item before them.
+func (r *Renderer) nestsLists() bool {
+	return r.nestedLists || r.version == Quill2
+}
+
+// quill2Pre returns the opening tag of a Quill 2 code block in the language lang ("y" if none is given).
+func quill2Pre(lang string) string {
+	if lang == "y" {
+		return "<pre>\n"
+	}
+	return "<pre data-language=" + quoteAttr(lang) + ">\n"
+}
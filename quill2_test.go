@@ -0,0 +1,93 @@
+package quill
+
+import "testing"
+
+func TestWithQuillVersion(t *testing.T) {
+
+	cases := map[string]struct {
+		ops    string
+		quill1 string
+		quill2 string
+	}{
+		"checklist": {
+			ops: `[{"insert":"a"},{"attributes":{"list":"checked"},"insert":"\n"},{"insert":"b"},{"attributes":{"list":"checked"},"insert":"\n"},` +
+				`{"insert":"c"},{"attributes":{"list":"unchecked"},"insert":"\n"}]`,
+			quill1: "<ol><li>a</li><li>b</li><li>c</li></ol>",
+			quill2: `<ul><li data-list="checked">a</li><li data-list="checked">b</li></ul><ul><li data-list="unchecked">c</li></ul>`,
+		},
+		"nested lists": {
+			ops: `[{"insert":"a"},{"attributes":{"list":"ordered"},"insert":"\n"},` +
+				`{"insert":"b"},{"attributes":{"list":"bullet","indent":1},"insert":"\n"},` +
+				`{"insert":"c"},{"attributes":{"list":"checked","indent":1},"insert":"\n"},` +
+				`{"insert":"d"},{"attributes":{"list":"ordered"},"insert":"\n"}]`,
+			quill1: `<ol><li>a</li></ol><ul><li class="indent-1">b</li></ul><ol><li class="indent-1">c</li><li>d</li></ol>`,
+			quill2: `<ol><li>a<ul><li>b</li></ul><ul><li data-list="checked">c</li></ul></li><li>d</li></ol>`,
+		},
+		"aligned list item": {
+			ops:    `[{"insert":"a"},{"attributes":{"list":"bullet","align":"right"},"insert":"\n"}]`,
+			quill1: `<ul><li class="ql-align-right">a</li></ul>`,
+			quill2: `<ul><li>a</li></ul>`,
+		},
+		"code block with language": {
+			ops:    `[{"insert":"x = 1"},{"attributes":{"code-block":"javascript"},"insert":"\n\n"},{"insert":"y"},{"attributes":{"code-block":"javascript"},"insert":"\n"}]`,
+			quill1: "<pre>x = 1\n\ny\n</pre>",
+			quill2: "<pre data-language=\"javascript\">\nx = 1\n\ny\n</pre>",
+		},
+		"code blocks in two languages": {
+			ops:    `[{"insert":"a"},{"attributes":{"code-block":"sql"},"insert":"\n"},{"insert":"b"},{"attributes":{"code-block":"css"},"insert":"\n"}]`,
+			quill1: "<pre>a\nb\n</pre>",
+			quill2: "<pre data-language=\"sql\">\na\n</pre><pre data-language=\"css\">\nb\n</pre>",
+		},
+		"code block": {
+			ops:    `[{"insert":"a < b"},{"attributes":{"code-block":true},"insert":"\n"},{"insert":"text\n"}]`,
+			quill1: "<pre>a &lt; b\n</pre><p>text</p>",
+			quill2: "<pre>\na &lt; b\n</pre><p>text</p>",
+		},
+		"indent": {
+			ops:    `[{"insert":"a"},{"attributes":{"indent":2,"align":"center"},"insert":"\n"}]`,
+			quill1: `<p class="indent-2 ql-align-center">a</p>`,
+			quill2: `<p class="ql-align-center ql-indent-2">a</p>`,
+		},
+		"empty lines": {
+			ops:    `[{"insert":"a\n\n\nb\n"}]`,
+			quill1: "<p>a</p><p><br></p><p>b</p>",
+			quill2: "<p>a</p><p><br></p><p><br></p><p>b</p>",
+		},
+		"link": {
+			ops:    `[{"attributes":{"link":"https://example.com"},"insert":"x"},{"insert":"\n"}]`,
+			quill1: `<p><a href="https://example.com" target="_blank" rel="nofollow noopener">x</a></p>`,
+			quill2: `<p><a href="https://example.com" rel="noopener noreferrer" target="_blank">x</a></p>`,
+		},
+	}
+
+	quill1 := NewRenderer()
+	quill2 := NewRenderer(WithQuillVersion(Quill2))
+
+	for name, c := range cases {
+		for _, v := range []struct {
+			r    *Renderer
+			want string
+		}{{quill1, c.quill1}, {quill2, c.quill2}} {
+			got, err := v.r.Render([]byte(c.ops))
+			if err != nil {
+				t.Errorf("%s: %s", name, err)
+				continue
+			}
+			if string(got) != v.want {
+				t.Errorf("%s: bad rendering (Quill %d)\ngot:  %s\nwant: %s", name, v.r.version+1, got, v.want)
+			}
+		}
+		// The HTML of Quill 2 can be imported back.
+		if name == "link" || name == "aligned list item" || name == "empty lines" || name == "code block" {
+			continue
+		}
+		imported, err := ImportHTML([]byte(c.quill2))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameJSON(t, imported, []byte(c.ops)) {
+			t.Errorf("%s: bad import of %s; got: %s", name, c.quill2, imported)
+		}
+	}
+
+}
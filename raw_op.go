@@ -1,6 +1,7 @@
 package quill
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 )
@@ -20,6 +21,8 @@ func (ro *rawOp) makeOp(o *Op) error {
 		return fmt.Errorf("op %+v lacks an insert", *ro)
 	}
 
+	var embed interface{} // the value of an embed
+
 	switch ins := ro.Insert.(type) {
 	case string:
 		// This op is a simple string insert.
@@ -32,7 +35,7 @@ func (ro *rawOp) makeOp(o *Op) error {
 		// There should be one item in the map (the element's key being the insert type).
 		for mk := range ins {
 			o.Type = mk
-			o.Data = extractString(ins[mk])
+			embed = ins[mk]
 			break
 		}
 	default:
@@ -51,10 +54,51 @@ func (ro *rawOp) makeOp(o *Op) error {
 		}
 	}
 
+	if o.Type != "text" {
+		o.Data = embedData(embed, o.Attrs)
+	}
+
 	return nil
 
 }
 
+// op makes a new Op out of the raw Delta op.
+func (ro *rawOp) op() (*Op, error) {
+	o := &Op{Attrs: make(map[string]string, len(ro.Attrs))}
+	if err := ro.makeOp(o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// embedData returns the data of an embed. The value of an embed may be an object, such as
+// {"src": "a.png", "alt": "A", "width": 300} for an image, in which case the data is taken from its src or url property
+// and its other properties are added to attrs, unless the attributes of the op set them.
+func embedData(v interface{}, attrs map[string]string) string {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return extractString(v)
+	}
+	var data string
+	for k, pv := range obj {
+		switch k {
+		case "src", "url":
+			if data == "" || k == "src" {
+				data = extractString(pv)
+			}
+		default:
+			if _, set := attrs[k]; !set {
+				if s := extractString(pv); s != "" {
+					attrs[k] = s
+				}
+			}
+		}
+	}
+	return data
+}
+
+// extractString returns the value of an insert or attribute as a string: "y" for true, and numbers without a fraction.
+// Numbers may be of any of the kinds that JSON decodes into or that a Delta built in Go holds.
 func extractString(v interface{}) string {
 	switch val := v.(type) {
 	case string:
@@ -65,6 +109,32 @@ func extractString(v interface{}) string {
 		}
 	case float64:
 		return strconv.FormatFloat(val, 'f', 0, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', 0, 32)
+	case int:
+		return strconv.FormatInt(int64(val), 10)
+	case int8:
+		return strconv.FormatInt(int64(val), 10)
+	case int16:
+		return strconv.FormatInt(int64(val), 10)
+	case int32:
+		return strconv.FormatInt(int64(val), 10)
+	case int64:
+		return strconv.FormatInt(val, 10)
+	case uint:
+		return strconv.FormatUint(uint64(val), 10)
+	case uint8:
+		return strconv.FormatUint(uint64(val), 10)
+	case uint16:
+		return strconv.FormatUint(uint64(val), 10)
+	case uint32:
+		return strconv.FormatUint(uint64(val), 10)
+	case uint64:
+		return strconv.FormatUint(val, 10)
+	case json.Number:
+		if f, err := val.Float64(); err == nil {
+			return strconv.FormatFloat(f, 'f', 0, 64)
+		}
 	}
 	return ""
 }
@@ -1,6 +1,7 @@
 package quill
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
 )
@@ -35,6 +36,14 @@ func TestRawOp_makeOp(t *testing.T) {
 				"image": "url-or-base64",
 			},
 		},
+		{
+			Insert: map[string]interface{}{
+				"image": map[string]interface{}{"src": "a.png", "alt": "an image", "width": float64(300)},
+			},
+			Attrs: map[string]interface{}{
+				"alt": "the image",
+			},
+		},
 	}
 
 	want := []Op{
@@ -68,6 +77,14 @@ func TestRawOp_makeOp(t *testing.T) {
 			Type:  "image",
 			Attrs: make(map[string]string), // like in code (already initialized)
 		},
+		{
+			Data: "a.png",
+			Type: "image",
+			Attrs: map[string]string{
+				"alt":   "the image", // The attributes of the op take precedence.
+				"width": "300",
+			},
+		},
 	}
 
 	o := new(Op)                         // reuse in loop
@@ -100,4 +117,10 @@ func TestExtractString(t *testing.T) {
 	if extractString(float64(3)) != "3" {
 		t.Errorf("failed float64 extract")
 	}
+	for _, v := range []interface{}{int(3), int8(3), int16(3), int32(3), int64(3), uint(3), uint8(3), uint16(3),
+		uint32(3), uint64(3), float32(3), json.Number("3")} {
+		if got := extractString(v); got != "3" {
+			t.Errorf("failed %T extract; got %q", v, got)
+		}
+	}
 }
@@ -0,0 +1,262 @@
+package quill
+
+import "sort"
+
+// A FormatFactory makes the Formatter for an insert type or an attribute of the Op. The Renderer doing the rendering is
+// given so that its settings can be respected. A FormatFactory may return nil if the Op should not get a format.
+type FormatFactory func(o *Op, r *Renderer) Formatter
+
+// A Registry maps each insert type (such as "text" or "image") and attribute name (such as "bold") to the FormatFactory
+// that makes its Formatter.
+//
+// A Registry is not safe for concurrent modification, but a Renderer keeps its own copy of the Registry given to it, so
+// a Registry may be changed and reused after it is set on a Renderer.
+type Registry struct {
+	factories map[string]FormatFactory
+}
+
+// NewRegistry returns a Registry holding all of the built-in formats.
+func NewRegistry() *Registry {
+	reg := &Registry{make(map[string]FormatFactory, len(builtinFormats))}
+	for name, f := range builtinFormats {
+		reg.factories[name] = f
+	}
+	return reg
+}
+
+// Register sets the FormatFactory for the insert type or attribute name, replacing any that is already registered.
+func (reg *Registry) Register(name string, f FormatFactory) {
+	if f == nil {
+		reg.Unregister(name)
+		return
+	}
+	if reg.factories == nil {
+		reg.factories = make(map[string]FormatFactory)
+	}
+	reg.factories[name] = f
+}
+
+// Unregister removes the FormatFactory for the insert type or attribute name. Attributes without a registered format
+// are ignored, and ops whose insert type has no registered format cannot be rendered.
+func (reg *Registry) Unregister(name string) {
+	delete(reg.factories, name)
+}
+
+// Lookup returns the FormatFactory registered for the insert type or attribute name, or nil if there is none.
+func (reg *Registry) Lookup(name string) FormatFactory {
+	return reg.factories[name]
+}
+
+// Names lists the registered insert types and attribute names in sorted order.
+func (reg *Registry) Names() []string {
+	names := make([]string, 0, len(reg.factories))
+	for name := range reg.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Clone returns a copy of the Registry.
+func (reg *Registry) Clone() *Registry {
+	c := &Registry{make(map[string]FormatFactory, len(reg.factories))}
+	for name, f := range reg.factories {
+		c.factories[name] = f
+	}
+	return c
+}
+
+// builtinFormats lists the formats that are registered by default.
+var builtinFormats = map[string]FormatFactory{
+	"text": func(*Op, *Renderer) Formatter {
+		return new(textFormat)
+	},
+	"header": func(o *Op, _ *Renderer) Formatter {
+		if !headerLevels[o.Attrs["header"]] {
+			return nil
+		}
+		return &headerFormat{
+			level: o.Attrs["header"],
+		}
+	},
+	"list": func(o *Op, r *Renderer) Formatter {
+		if r.nestsLists() {
+			return newNestedListFormat(o, r)
+		}
+		lf := &listFormat{
+			indent: indentDepths[o.Attrs["indent"]],
+		}
+		if o.Attrs["list"] == "bullet" {
+			lf.lType = "ul"
+		} else {
+			lf.lType = "ol"
+		}
+		return lf
+	},
+	"blockquote": func(*Op, *Renderer) Formatter {
+		return new(blockQuoteFormat)
+	},
+	"align": func(o *Op, r *Renderer) Formatter {
+		if r.version == Quill2 && o.HasAttr("list") {
+			return nil // Quill 2 does not write the alignment of list items.
+		}
+		if !quillAligns[o.Attrs["align"]] {
+			return nil
+		}
+		af := &alignFormat{
+			val: o.Attrs["align"],
+		}
+		if r.styles != nil {
+			if af.style = r.styles.Aligns[af.val]; af.style == "" {
+				return nil
+			}
+		} else if af.class = r.className("align", af.val); af.class == "" {
+			return nil
+		}
+		return af
+	},
+	"image": func(o *Op, r *Renderer) Formatter {
+		return newImageFormat(o, &r.urls)
+	},
+	"formula": func(o *Op, r *Renderer) Formatter {
+		if r.email {
+			return &formulaFormat{tex: o.Data, plain: true}
+		}
+		return newFormulaFormat(o)
+	},
+	"video": func(o *Op, r *Renderer) Formatter {
+		if r.email {
+			// Email clients do not play embedded videos.
+			link, _ := r.urls.Resolve(o.Data, LinkURL)
+			return &videoFormat{link: link}
+		}
+		return newVideoFormat(o, &r.urls)
+	},
+	"divider": func(*Op, *Renderer) Formatter {
+		return new(dividerFormat)
+	},
+	"page-break": func(_ *Op, r *Renderer) Formatter {
+		pf := new(pageBreakFormat)
+		if r.styles == nil {
+			pf.class = r.className("page-break", "y") // Inline styles leave out the class.
+		}
+		return pf
+	},
+	"link": func(o *Op, r *Renderer) Formatter {
+		lf := newLinkFormat(o, &r.urls)
+		if l, ok := lf.(*linkFormat); ok && r.version == Quill2 {
+			l.rel = "noopener noreferrer"
+		}
+		return lf
+	},
+	"bold": func(*Op, *Renderer) Formatter {
+		return new(boldFormat)
+	},
+	"size": func(o *Op, r *Renderer) Formatter {
+		if sizeStyles[o.Attrs["size"]] == "" {
+			return nil
+		}
+		sf := &sizeFormat{
+			val: o.Attrs["size"],
+		}
+		if r.styles != nil {
+			if sf.style = r.styles.Sizes[sf.val]; sf.style == "" {
+				return nil
+			}
+		} else if sf.class = r.className("size", sf.val); sf.class == "" {
+			return nil
+		}
+		return sf
+	},
+	"font": func(o *Op, r *Renderer) Formatter {
+		ff := &fontFormat{
+			val: o.Attrs["font"],
+		}
+		if r.styles != nil {
+			if ff.style = r.styles.Fonts[ff.val]; ff.style == "" {
+				return nil
+			}
+		} else if ff.class = r.className("font", ff.val); ff.class == "" {
+			return nil
+		}
+		return ff
+	},
+	"code": func(*Op, *Renderer) Formatter {
+		return new(codeFormat)
+	},
+	"direction": func(o *Op, r *Renderer) Formatter {
+		if o.Attrs["direction"] != "rtl" {
+			return nil
+		}
+		df := new(directionFormat)
+		if r.styles != nil {
+			df.style = "direction:rtl;"
+		} else if df.class = r.className("direction", "rtl"); df.class == "" {
+			return nil
+		}
+		return df
+	},
+	"italic": func(*Op, *Renderer) Formatter {
+		return new(italicFormat)
+	},
+	"underline": func(*Op, *Renderer) Formatter {
+		return new(underlineFormat)
+	},
+	"color": func(o *Op, r *Renderer) Formatter {
+		return newColorFormat("color", o, &r.colors)
+	},
+	"indent": func(o *Op, r *Renderer) Formatter {
+		if r.nestsLists() && o.HasAttr("list") {
+			return nil // The indent is shown by the nesting.
+		}
+		if !quillIndents[o.Attrs["indent"]] {
+			return nil
+		}
+		inf := &indentFormat{
+			in: o.Attrs["indent"],
+		}
+		if r.styles != nil {
+			pads := r.styles.Indents
+			if o.HasAttr("list") && r.styles.ListIndents != nil {
+				pads = r.styles.ListIndents
+			}
+			pad := pads[inf.in]
+			if pad == "" {
+				return nil
+			}
+			if o.Attrs["direction"] == "rtl" {
+				inf.style = "padding-right:" + pad + ";"
+			} else {
+				inf.style = "padding-left:" + pad + ";"
+			}
+		} else if inf.class = r.className("indent", inf.in); inf.class == "" {
+			return nil
+		}
+		return inf
+	},
+	"strike": func(*Op, *Renderer) Formatter {
+		return new(strikeFormat)
+	},
+	"background": func(o *Op, r *Renderer) Formatter {
+		return newColorFormat("background", o, &r.colors)
+	},
+	"script": func(o *Op, _ *Renderer) Formatter {
+		switch o.Attrs["script"] {
+		case "super":
+			return &scriptFormat{t: "sup"}
+		case "sub":
+			return &scriptFormat{t: "sub"}
+		}
+		return nil
+	},
+	"code-block": func(o *Op, r *Renderer) Formatter {
+		cf := &codeBlockFormat{o: o}
+		if r.version == Quill2 {
+			cf.pre = quill2Pre(o.Attrs["code-block"])
+		}
+		if r.highlight != NoHighlighting {
+			cf.lang = o.Attrs["code-block"] // Each language is highlighted in a pre element of its own.
+		}
+		return cf
+	},
+}
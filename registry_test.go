@@ -0,0 +1,88 @@
+package quill
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+
+	reg := NewRegistry()
+
+	if reg.Lookup("bold") == nil {
+		t.Fatal("built-in format bold is not registered")
+	}
+
+	reg.Unregister("size")
+	reg.Unregister("background")
+	if reg.Lookup("size") != nil || reg.Lookup("background") != nil {
+		t.Errorf("formats were not unregistered")
+	}
+
+	reg.Register("mention", func(o *Op, _ *Renderer) Formatter { return new(boldFormat) })
+	if reg.Lookup("mention") == nil {
+		t.Errorf("format was not registered")
+	}
+
+	want := []string{"align", "blockquote", "bold", "code", "code-block", "color", "direction", "divider", "font",
+		"formula", "header", "image", "indent", "italic", "link", "list", "mention", "page-break", "script", "strike",
+		"text", "underline", "video"}
+	if got := reg.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong names listed; got %v", got)
+	}
+
+	c := reg.Clone()
+	c.Unregister("bold")
+	if reg.Lookup("bold") == nil {
+		t.Errorf("changing a clone changed the original")
+	}
+
+	var empty Registry
+	empty.Register("text", builtinFormats["text"])
+	if got := empty.Names(); !reflect.DeepEqual(got, []string{"text"}) {
+		t.Errorf("zero Registry not usable; got %v", got)
+	}
+
+}
+
+func TestRenderer_registry(t *testing.T) {
+
+	ops := []byte(`[{"attributes":{"size":"huge","background":"#ff0000","bold":true},"insert":"big"},{"insert":"\n"}]`)
+
+	// Two renderers with different format sets.
+	reg := NewRegistry()
+	reg.Unregister("size")
+	plain := NewRenderer(WithRegistry(reg), WithoutFormats("background"))
+	reg.Register("size", builtinFormats["size"]) // Does not change the Renderer.
+
+	got, err := plain.Render(ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<p><strong>big</strong></p>"; string(got) != want {
+		t.Errorf("bad rendering without formats; got: %s", got)
+	}
+
+	custom := NewRenderer(WithFormat("bold", func(*Op, *Renderer) Formatter { return new(underlineFormat) }))
+	got, err = custom.Render(ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<p><u><span class="ql-size-huge"><span style="background-color:#ff0000;">big</span></span></u></p>`; string(got) != want {
+		t.Errorf("bad rendering with overridden format; got: %s", got)
+	}
+
+	if _, err = NewRenderer(WithoutFormats("text")).Render(ops); err == nil {
+		t.Errorf("no error rendering text without a text format")
+	}
+
+	// A nil Registry puts back the built-in formats.
+	got, err = NewRenderer(WithRegistry(reg), WithoutFormats("bold"), WithRegistry(nil)).Render(ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := defaultRenderer.Render(ops); string(got) != string(want) {
+		t.Errorf("bad rendering with a nil Registry; got: %s", got)
+	}
+
+}
@@ -1,120 +1,291 @@
 // Package quill takes a Quill-based Delta (https://github.com/quilljs/delta) as a JSON array of `insert` operations
 // and renders the defined HTML document.
 //
-// This library is designed to be easily extendable. Simply call RenderExtended with a function that may provide its
-// own formats for certain kinds of ops and attributes.
+// This library is designed to be easily extendable. Create a Renderer with the options you need (such as a function
+// that may provide its own formats for certain kinds of ops and attributes) and use it for all of your rendering, or
+// simply call Render with the built-in settings.
 package quill
 
 import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
-	"strconv"
+	"sort"
 	"strings"
 )
 
 // Render takes a Delta array of insert operations and returns the rendered HTML using the built-in settings.
 // If an error occurs while rendering, any HTML already rendered is returned.
 func Render(ops []byte) ([]byte, error) {
-	return RenderExtended(ops, nil)
+	return defaultRenderer.Render(ops)
 }
 
 // RenderExtended takes a Delta array of insert operations and, optionally, a function that may provide a Formatter to
 // customize the way certain kinds of inserts are rendered, and returns the rendered HTML. If the given Formatter is nil,
 // then the default one that is built in is used. If an error occurs while rendering, any HTML already rendered is returned.
+//
+// All text inserted by the ops is HTML-escaped, as are all class and style attribute values.
 func RenderExtended(ops []byte, customFormats func(string, *Op) Formatter) ([]byte, error) {
+	if customFormats == nil {
+		return defaultRenderer.Render(ops)
+	}
+	return NewRenderer(WithCustomFormats(customFormats)).Render(ops)
+}
+
+// RenderTrusted works like RenderExtended except that the text of inserts is written out without being HTML-escaped,
+// so any markup in the Delta is kept as is. Use it only for Deltas that come from a trusted source. Attribute values
+// are still quoted properly.
+func RenderTrusted(ops []byte, customFormats func(string, *Op) Formatter) ([]byte, error) {
+	return NewRenderer(WithCustomFormats(customFormats), WithTrustedInput()).Render(ops)
+}
+
+// Render takes a Delta array of insert operations and returns the HTML rendered with the settings of the Renderer.
+// If an error occurs while rendering, any HTML already rendered is returned.
+func (r *Renderer) Render(ops []byte) ([]byte, error) {
+
+	raw, err := r.decode(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := newRenderVars(r)
+	bs := blockSplitter{r: r, emit: vars.writeBlock}
+
+	for i := range raw {
+		o, err := raw[i].op()
+		if err != nil {
+			return vars.finalBuf.Bytes(), err
+		}
+		if err = bs.add(o); err != nil {
+			return vars.finalBuf.Bytes(), err
+		}
+	}
+
+	if err := bs.end(); err != nil {
+		return vars.finalBuf.Bytes(), err
+	}
+
+	if err := vars.finish(); err != nil {
+		return vars.finalBuf.Bytes(), err
+	}
+
+	return vars.finalBuf.Bytes(), nil
+
+}
+
+// decode decodes the raw ops of a Delta, enforcing the limits of the Renderer.
+func (r *Renderer) decode(ops []byte) ([]rawOp, error) {
+
+	if r.maxSize > 0 && len(ops) > r.maxSize {
+		return nil, ErrInputTooLarge
+	}
 
 	raw := make([]rawOp, 0, 12)
 	if err := json.Unmarshal(ops, &raw); err != nil {
 		return nil, err
 	}
 
-	vars := renderVars{
-		fs:  make(formatState, 0, 4),
-		fms: make([]*Format, 0, 4),
-		o:   Op{Attrs: make(map[string]string, 3)},
+	if r.maxOps > 0 && len(raw) > r.maxOps {
+		return nil, ErrTooManyOps
 	}
 
-	for i := range raw {
+	return raw, nil
+
+}
 
-		if err := raw[i].makeOp(&vars.o); err != nil {
+// RenderDocument returns the HTML of the Document rendered with the settings of the Renderer. If an error occurs while
+// rendering, any HTML already rendered is returned.
+func (r *Renderer) RenderDocument(doc *Document) ([]byte, error) {
+
+	vars := newRenderVars(r)
+
+	for _, b := range doc.Blocks {
+		if err := vars.writeBlock(b); err != nil {
 			return vars.finalBuf.Bytes(), err
 		}
+	}
 
-		vars.fms = vars.fms[:0] // Reset the slice for the current Op iteration.
+	if err := vars.finish(); err != nil {
+		return vars.finalBuf.Bytes(), err
+	}
 
-		// To set up fms, first check the Op insert type.
-		typeFmTer := vars.o.getFormatter(vars.o.Type, customFormats)
-		if typeFmTer == nil {
-			return vars.finalBuf.Bytes(), fmt.Errorf("quill: an op does not have a format defined for its type: %v", raw[i])
-		}
-		vars.o.addFmTer(&vars, typeFmTer)
+	return vars.finalBuf.Bytes(), nil
 
-		// Get a Formatter out of each of the attributes.
-		for attr := range vars.o.Attrs {
-			vars.o.addFmTer(&vars, vars.o.getFormatter(attr, customFormats))
-		}
+}
 
-		// Open a block element, write its body, and close it to move on only when the ending "\n" of the block is reached.
-		if strings.IndexByte(vars.o.Data, '\n') != -1 {
+// RenderTo renders the Delta array of insert operations given by the default settings, like Render, but it reads the
+// Delta from in and writes the HTML to w as the rendering goes. See Renderer.RenderTo.
+func RenderTo(w io.Writer, in io.Reader) error {
+	return defaultRenderer.RenderTo(w, in)
+}
+
+// RenderTo works like Render, but it decodes the ops of the Delta one at a time from in and writes each block of HTML
+// to w as soon as the block is complete. Memory use is bounded by the size of the largest block rather than by the size
+// of the whole document, so RenderTo is suited to very large documents.
+//
+// If an error occurs, any HTML already rendered has been written to w.
+func (r *Renderer) RenderTo(w io.Writer, in io.Reader) error {
 
-			// Extract text from between the block-terminating line feeds and write each part as its own Op.
-			split := strings.Split(vars.o.Data, "\n")
+	if r.maxSize > 0 {
+		in = &limitedReader{in, r.maxSize}
+	}
 
-			for j := range split {
+	dec := json.NewDecoder(in)
 
-				vars.o.Data = split[j]
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('[') {
+		return fmt.Errorf("quill: a Delta must be a JSON array of ops, not start with %v", tok)
+	}
 
-				// If the current o.Data still has an "\n" following (its not the last in split), then it ends a block.
-				if j < len(split)-1 {
-					vars.o.writeBlock(&vars)
+	vars := newRenderVars(r)
+	bs := blockSplitter{r: r, emit: func(b *Block) error {
+		if err := vars.writeBlock(b); err != nil {
+			vars.flush(w) // Write out what was rendered before the error.
+			return err
+		}
+		return vars.flush(w)
+	}}
 
-				} else if vars.o.Data != "" { // If the last element in split is just "" then the last character in the rawOp is "\n".
+	for n := 1; dec.More(); n++ {
 
-					vars.o.writeInline(&vars)
+		if r.maxOps > 0 && n > r.maxOps {
+			return ErrTooManyOps
+		}
 
-				}
+		var ro rawOp
+		if err := dec.Decode(&ro); err != nil {
+			return err
+		}
 
-			}
+		o, err := ro.op()
+		if err != nil {
+			return err
+		}
 
-		} else {
-			vars.o.writeInline(&vars)
+		if err = bs.add(o); err != nil {
+			return err
 		}
 
 	}
 
-	// Before writing out the final buffer, close the last remaining tags set by a FormatWrapper.
-	// The FormatWrapper should see that all styling is now done.
-	vars.fs.closePrevious(&vars.finalBuf, blankOp(), true)
+	if _, err := dec.Token(); err != nil { // the closing "]"
+		return err
+	}
 
-	return vars.finalBuf.Bytes(), nil
+	if err := bs.end(); err != nil {
+		return err
+	}
+
+	if err := vars.finish(); err != nil {
+		vars.flush(w)
+		return err
+	}
+
+	return vars.flush(w)
 
 }
 
-// renderVars combines the variables created in RenderExtended into a single allocation.
+// newRenderVars sets up the variables for rendering a single document with r.
+func newRenderVars(r *Renderer) *renderVars {
+	return &renderVars{
+		fs:  make(formatState, 0, 4),
+		fms: make([]*Format, 0, 4),
+		r:   r,
+	}
+}
+
+// finish writes out any code block still buffered and closes the last remaining tags set by a FormatWrapper. The
+// FormatWrapper should see that all styling is now done.
+func (vars *renderVars) finish() error {
+	if err := vars.flushCode(); err != nil {
+		return err
+	}
+	vars.closeLists(nil)
+	vars.fs.closePrevious(&vars.finalBuf, blankOp(), true)
+	return nil
+}
+
+// flush writes out the completed part of the final output to w.
+func (vars *renderVars) flush(w io.Writer) error {
+	if vars.finalBuf.Len() == 0 {
+		return nil
+	}
+	_, err := w.Write(vars.finalBuf.Bytes())
+	vars.finalBuf.Reset()
+	return err
+}
+
+// renderVars combines the variables used while rendering a document into a single allocation.
 type renderVars struct {
 	finalBuf bytes.Buffer // the final output
 	tempBuf  bytes.Buffer // temporary buffer reused for each block element
 	fs       formatState  // the tags currently open in the order in which they were opened
 	fms      []*Format    // reused slice for the the Formatter types defined for each Op
-	o        Op           // an Op to reuse for all iterations
+	o        Op           // the inline Op currently being written (a copy of the inline of the Document)
+	bo       Op           // the Op standing for the block currently being written
+	embed    FormatWriter // the FormatWriter that writes the body of the current inline, if any
+	block    blockTag     // the tag of the current block
 	wraped   bool
+	lists    []*nestedListFormat // the formats of the items whose nested lists are open, each with an open item
+	code     []*Block            // the lines of the code block being highlighted
+	r        *Renderer           // the Renderer doing the rendering
+}
+
+// A blockTag is the single HTML tag into which all of the block-level formats of a block are merged.
+type blockTag struct {
+	tagName   string
+	classes   []string
+	styles    []string // the CSS declarations of the style attribute
+	attrs     string   // any other attributes, written before the class
+	leaveOpen bool     // the tag is closed later, as a nested list item is
+	flow      bool     // the block holds an element, such as a figure, that may not be inside of a paragraph
+}
+
+// writeText writes the text of an insert to buf, escaping it unless the input is trusted.
+func (vars *renderVars) writeText(buf *bytes.Buffer, s string) {
+	if vars.r.trusted {
+		buf.WriteString(s)
+		return
+	}
+	textEscaper.WriteString(buf, s)
+}
+
+// setFormats sets up fms with the formats of the Op: first the format of its type, and then the format of each of its
+// attributes.
+func (vars *renderVars) setFormats(o *Op) error {
+
+	vars.fms = vars.fms[:0] // Reset the slice for the current Op.
+	vars.embed = nil
+
+	typeFmTer := o.getFormatter(o.Type, vars.r)
+	if typeFmTer == nil {
+		return fmt.Errorf("quill: an op does not have a format defined for its type: %v", *o)
+	}
+	o.addFmTer(vars, typeFmTer)
+
+	for attr := range o.Attrs {
+		o.addFmTer(vars, o.getFormatter(attr, vars.r))
+	}
+
+	return nil
+
 }
 
 // addFmTer adds the format from fmTer to fms (the temporary, current Op's formats) if the format is not already set in the
-// current format state. All FormatWrapper formats are added regardless of whether they are already set on fs. Data is written
-// to the temporary buffer only.
+// current format state. All FormatWrapper formats are added regardless of whether they are already set on fs. A
+// FormatWriter is kept to write the body of the Op.
 func (o *Op) addFmTer(vars *renderVars, fmTer Formatter) {
 	if fmTer == nil {
 		return
 	}
 	fm := fmTer.Fmt()
 	if fm == nil {
-		// Check if the format is a FormatWriter. If it is, just write it out and continue.
+		// Check if the format is a FormatWriter. If it is, it will write out the body of the Op.
 		if wr, ok := fmTer.(FormatWriter); ok {
-			wr.Write(&vars.tempBuf)
-			o.Data = ""
+			vars.embed = wr
 		}
 		return
 	}
@@ -125,11 +296,27 @@ func (o *Op) addFmTer(vars *renderVars, fmTer Formatter) {
 		vars.fms = append(vars.fms, fm)
 		return
 	}
+	if fm.Place == Tag && !isTagName(fm.Val) {
+		return // The value would be written raw into the markup.
+	}
 	if !vars.fs.hasSet(fmTer.Fmt()) {
 		vars.fms = append(vars.fms, fm)
 	}
 }
 
+// isTagName says if s is a plain HTML tag name: a lowercase letter followed by lowercase letters and digits.
+func isTagName(s string) bool {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		if c := s[i]; (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
 // An Op is a Delta insert operations (https://github.com/quilljs/delta#insert) that has been converted into this format for
 // usability with the type safety in Go.
 type Op struct {
@@ -139,67 +326,152 @@ type Op struct {
 }
 
 // writeBlock writes a block element (which may be nested inside another block element if it is a FormatWrapper).
-// The opening HTML tag of a block element is written to the main buffer only after the "\n" character terminating the
-// block is reached (the Op with the "\n" character holds the information about the block element).
-func (o *Op) writeBlock(vars *renderVars) {
+// The block-level formats come from the attributes of the "\n" terminating the block, which are known before any of the
+// inline content of the block is written. The body of the block is written to the temporary buffer, which is copied to
+// the main buffer once the block is complete.
+func (vars *renderVars) writeBlock(b *Block) error {
+
+	if vars.r.highlight != NoHighlighting {
+		lang, isCode := b.Attrs["code-block"]
+		if len(vars.code) > 0 && (!isCode || vars.code[0].Attrs["code-block"] != lang) {
+			if err := vars.flushCode(); err != nil {
+				return err
+			}
+		}
+		if isCode {
+			vars.bufferCode(b)
+			return nil
+		}
+	}
 
-	// Close the inline formats opened within the block to the tempBuf and block formats of wrappers to finalBuf.
-	closedTemp := make(formatState, 0, 1)
+	// The block splitter has put each embed that is a block of its own in a Block by itself.
+	if len(b.Inlines) == 1 && vars.r.isBlockEmbed(b.Inlines[0]) {
+		vars.writeBlockEmbed(b)
+		return nil
+	}
 
-	for i := len(vars.fs) - 1; i >= 0; i-- { // Start with the last format opened.
+	if err := vars.openBlock(b); err != nil {
+		return err
+	}
+	for _, in := range b.Inlines {
+		vars.o = *in // Formatters may change the Op, but the Document must stay as it is.
+		if err := vars.setFormats(&vars.o); err != nil {
+			return err
+		}
+		vars.writeInline()
+	}
+	vars.closeBlock()
 
-		f := vars.fs[i]
+	return nil
 
-		// If this format is not set on the current Op, close it.
-		if (!f.wrap && !f.fm.HasFormat(o)) || (f.wrap && f.fm.(FormatWrapper).Close(vars.fs, o, true)) {
+}
 
-			// If we need to close a tag after which there are tags that should stay open, close the following tags for now.
-			if i < len(vars.fs)-1 {
-				for ij := len(vars.fs) - 1; ij > i; ij-- {
-					closedTemp.add(vars.fs[ij])
-					if f.wrap && f.Block {
-						vars.fs.pop(&vars.finalBuf)
-					} else {
-						vars.fs.pop(&vars.tempBuf)
-					}
-				}
-			}
+// isBlockEmbed says if the inline is an embed that is written as a block of its own.
+func (r *Renderer) isBlockEmbed(in *Op) bool {
+	if in.Type == "text" {
+		return false
+	}
+	be, ok := in.getFormatter(in.Type, r).(BlockEmbed)
+	return ok && be.IsBlock()
+}
 
-			if f.wrap && f.Block {
-				vars.fs.pop(&vars.finalBuf)
+// embedFormats returns the formats of the attributes of a line that are written on a block embed that ends the line:
+// the block-level formats written as classes or styles, such as an alignment. Formats that make a block of their own,
+// such as headers and lists, are left out.
+func (r *Renderer) embedFormats(attrs map[string]string) map[string]*Format {
+	o := &Op{Type: "text", Attrs: attrs}
+	fms := make(map[string]*Format)
+	for attr := range attrs {
+		fmTer := o.getFormatter(attr, r)
+		if _, ok := fmTer.(FormatWrapper); ok || fmTer == nil {
+			continue
+		}
+		if fm := fmTer.Fmt(); fm != nil && fm.Block && (fm.Place == Class || fm.Place == Style) {
+			fms[attr] = fm
+		}
+	}
+	return fms
+}
+
+// writeBlockEmbed writes the block b, which holds only an embed that is a block of its own, at the top level: the
+// FormatWrapper formats of blocks (such as lists and code blocks) and any nested lists are closed first, and the blocks
+// that follow open them again as they need. The classes and styles of the block, such as an alignment, are given to
+// the embed if it can write them. A link is still written around the embed, but other inline formats (such as bold)
+// cannot hold a block and are left out.
+func (vars *renderVars) writeBlockEmbed(b *Block) {
+
+	vars.closeLists(nil)
+	vars.fs.closePrevious(&vars.finalBuf, blankOp(), true)
+	vars.bo = *blankOp()
+	vars.block = blockTag{}
+
+	vars.o = *b.Inlines[0]
+	o := &vars.o
+	vars.fms = vars.fms[:0]
+	vars.embed = nil
+	o.addFmTer(vars, o.getFormatter(o.Type, vars.r)) // The embed has a format, or it would not be a block.
+	if o.HasAttr("link") {
+		o.addFmTer(vars, o.getFormatter("link", vars.r))
+	}
+
+	if es, ok := vars.embed.(embedStyler); ok {
+		var classes, styles []string
+		for _, fm := range vars.r.embedFormats(b.Attrs) {
+			if fm.Place == Class {
+				classes = append(classes, fm.Val)
 			} else {
-				vars.fs.pop(&vars.tempBuf)
+				styles = append(styles, fm.Val)
 			}
-
 		}
-
+		sort.Strings(classes) // The formats were set in the random order of the attributes map.
+		sort.Strings(styles)
+		es.setStyles(classes, styles)
 	}
 
-	// Re-open the temporarily closed formats.
-	closedTemp.writeFormats(&vars.tempBuf)
-	vars.fs = append(vars.fs, closedTemp...) // Copy after the sorting.
+	vars.writeInline()
+	vars.closeBlock()
+
+}
+
+// openBlock closes the FormatWrapper formats of blocks that the block does not continue, opens the FormatWrapper
+// formats that the block needs, and merges the block-level formats of the block into a single tag.
+func (vars *renderVars) openBlock(b *Block) error {
 
-	var block struct {
-		tagName string
-		classes []string
-		style   string
+	vars.bo = Op{Type: "text", Attrs: b.Attrs}
+	o := &vars.bo
+	if err := vars.setFormats(o); err != nil {
+		return err
+	}
+
+	// Only FormatWrapper formats of blocks and nested lists are still open, and these write out their closing wraps to
+	// finalBuf.
+	nl := vars.nestedList()
+	vars.closeLists(nl)
+	vars.fs.closePrevious(&vars.finalBuf, o, true)
+
+	vars.block = blockTag{}
+	if nl != nil {
+		vars.openLists(nl)
+		vars.block.attrs = nl.attrs
+		vars.block.leaveOpen = true
 	}
 
 	// Merge all formats into a single tag.
 	for i := range vars.fms {
 		fm := vars.fms[i]
 		// Apply only block-level formats.
-		if fm.Block {
-			v := fm.Val
-			switch fm.Place {
-			case Tag:
-				// If an opening tag is not specified by the Op insert type, it may be specified by an attribute.
-				block.tagName = v // Override whatever value is set.
-			case Class:
-				block.classes = append(block.classes, v)
-			case Style:
-				block.style += v
-			}
+		if !fm.Block {
+			continue
+		}
+		v := fm.Val
+		switch fm.Place {
+		case Tag:
+			// If an opening tag is not specified by the Op insert type, it may be specified by an attribute.
+			vars.block.tagName = v // Override whatever value is set.
+		case Class:
+			vars.block.classes = append(vars.block.classes, v)
+		case Style:
+			vars.block.styles = append(vars.block.styles, v)
 		}
 		// Write out all of FormatWrapper opening text (if there is any).
 		if fm.wrap && fm.fm.(FormatWrapper).Open(vars.fs, o) {
@@ -209,10 +481,29 @@ func (o *Op) writeBlock(vars *renderVars) {
 		}
 	}
 
-	// Avoid empty paragraphs and "\n" in the output for text blocks.
+	return nil
+
+}
+
+// closeBlock closes the inline formats still open in the block and writes out the complete block element.
+func (vars *renderVars) closeBlock() {
+
+	// Close all formats opened within the block, leaving only the FormatWrapper formats of blocks open.
+	for len(vars.fs) > 0 {
+		if f := vars.fs[len(vars.fs)-1]; f.wrap && f.Block {
+			break
+		}
+		vars.fs.pop(&vars.tempBuf)
+	}
+
+	o := &vars.bo
+	block := &vars.block
+
+	// Avoid empty paragraphs and "\n" in the output for text blocks. Quill 2 writes every empty line.
+	var lineBreak bool
 	if o.Data == "" && block.tagName == "p" && vars.tempBuf.Len() == 0 {
-		if !vars.wraped {
-			o.Data = "<br>"
+		if !vars.wraped || vars.r.version == Quill2 {
+			lineBreak = true
 			vars.wraped = true
 		} else {
 			block.tagName = "" //skip repeat <br>
@@ -221,22 +512,29 @@ func (o *Op) writeBlock(vars *renderVars) {
 		vars.wraped = false
 	}
 
+	if block.flow && block.tagName == "p" {
+		block.tagName = "div" // A paragraph cannot hold a figure.
+	}
+
 	if block.tagName != "" {
 		vars.finalBuf.WriteByte('<')
 		vars.finalBuf.WriteString(block.tagName)
-		vars.finalBuf.WriteString(classesList(block.classes))
-		if block.style != "" {
-			vars.finalBuf.WriteString(" style=")
-			vars.finalBuf.WriteString(strconv.Quote(block.style))
-		}
+		vars.finalBuf.WriteString(block.attrs)
+		sort.Strings(block.classes) // The formats were set in the random order of the attributes map.
+		sort.Strings(block.styles)
+		vars.finalBuf.WriteString(styleAttrs(block.classes, block.styles))
 		vars.finalBuf.WriteByte('>')
 	}
 
+	vars.writeText(&vars.finalBuf, o.Data) // Write any data that a FormatWrapper put on the block (usually blank).
+
 	vars.finalBuf.Write(vars.tempBuf.Bytes()) // Copy the temporary buffer to the final output.
 
-	vars.finalBuf.WriteString(o.Data) // Copy the data of the current Op (usually just "<br>" or blank).
+	if lineBreak {
+		vars.finalBuf.WriteString("<br>")
+	}
 
-	if block.tagName != "" {
+	if block.tagName != "" && !block.leaveOpen {
 		closeTag(&vars.finalBuf, block.tagName)
 	}
 
@@ -244,8 +542,10 @@ func (o *Op) writeBlock(vars *renderVars) {
 
 }
 
-// writeInline writes to the temporary buffer.
-func (o *Op) writeInline(vars *renderVars) {
+// writeInline writes the current inline Op to the temporary buffer.
+func (vars *renderVars) writeInline() {
+
+	o := &vars.o
 
 	vars.fs.closePrevious(&vars.tempBuf, o, false)
 
@@ -270,7 +570,15 @@ func (o *Op) writeInline(vars *renderVars) {
 	addNow.writeFormats(&vars.tempBuf)
 	vars.fs = append(vars.fs, addNow...) // Copy after the sorting.
 
-	vars.tempBuf.WriteString(o.Data)
+	if vars.embed != nil {
+		if imf, ok := vars.embed.(*imageFormat); ok && imf.caption != "" && imf.src != "" {
+			vars.block.flow = true
+		}
+		vars.embed.Write(&vars.tempBuf)
+		return
+	}
+
+	vars.writeText(&vars.tempBuf, o.Data)
 
 }
 
@@ -281,77 +589,16 @@ func (o *Op) HasAttr(attr string) bool {
 
 // getFormatter returns a formatter based on the keyword (either "text" or "" or an attribute name) and the Op settings.
 // For every Op, first its Type is passed through here as the keyword, and then its attributes.
-func (o *Op) getFormatter(keyword string, customFormats func(string, *Op) Formatter) Formatter {
+func (o *Op) getFormatter(keyword string, r *Renderer) Formatter {
 
-	if customFormats != nil {
-		if custom := customFormats(keyword, o); custom != nil {
+	if r.customFormats != nil {
+		if custom := r.customFormats(keyword, o); custom != nil {
 			return custom
 		}
 	}
 
-	switch keyword { // This is the list of currently recognized "keywords".
-	case "text":
-		return new(textFormat)
-	case "header":
-		return &headerFormat{
-			level: o.Attrs["header"],
-		}
-	case "list":
-		lf := &listFormat{
-			indent: indentDepths[o.Attrs["indent"]],
-		}
-		if o.Attrs["list"] == "bullet" {
-			lf.lType = "ul"
-		} else {
-			lf.lType = "ol"
-		}
-		return lf
-	case "blockquote":
-		return new(blockQuoteFormat)
-	case "align":
-		return &alignFormat{
-			val: o.Attrs["align"],
-		}
-	case "image":
-		return &imageFormat{
-			src: o.Data,
-		}
-	case "link":
-		return &linkFormat{
-			href: o.Attrs["link"],
-		}
-	case "bold":
-		return new(boldFormat)
-	case "size":
-		return sizeFormat(o.Attrs["size"])
-	case "italic":
-		return new(italicFormat)
-	case "underline":
-		return new(underlineFormat)
-	case "color":
-		return &colorFormat{
-			c: o.Attrs["color"],
-		}
-	case "indent":
-		return &indentFormat{
-			in: o.Attrs["indent"],
-		}
-	case "strike":
-		return new(strikeFormat)
-	case "background":
-		return &bkgFormat{
-			c: o.Attrs["background"],
-		}
-	case "script":
-		sf := new(scriptFormat)
-		if o.Attrs["script"] == "super" {
-			sf.t = "sup"
-		} else {
-			sf.t = "sub"
-		}
-		return sf
-	case "code-block":
-		return &codeBlockFormat{o}
+	if factory := r.formats.Lookup(keyword); factory != nil {
+		return factory(o, r)
 	}
 
 	return nil
@@ -373,13 +620,15 @@ type Formatter interface {
 	HasFormat(*Op) bool // Say if the Op has the Format that Fmt returns.
 }
 
-// A FormatWriter can write the body of an Op in a custom way (useful for embeds).
+// A FormatWriter can write the body of an Op in a custom way (useful for embeds). What is written is not escaped, so
+// any values taken from the Op must be escaped by the FormatWriter (see html.EscapeString).
 type FormatWriter interface {
 	Formatter
 	Write(io.Writer) // Write the entire body of the element.
 }
 
-// A FormatWrapper wraps text with additional text of any kind (such as "<ul>" for lists).
+// A FormatWrapper wraps text with additional text of any kind (such as "<ul>" for lists). The wraps are not escaped, so
+// any values taken from the Op must be escaped by the FormatWrapper (see html.EscapeString).
 type FormatWrapper interface {
 	Formatter
 	Wrap() (pre, post string)        // Say what opening and closing wraps will be written.
@@ -387,8 +636,22 @@ type FormatWrapper interface {
 	Close([]*Format, *Op, bool) bool // Given the open formats, current Op, and if the Op closes a block, say if to write the post string.
 }
 
+// A BlockEmbed is a FormatWriter for an embed (such as a divider) that is not part of the text of a line but a block of
+// its own. The renderer ends any block open before the embed and closes the FormatWrapper formats of blocks, writes the
+// embed at the top level, and then goes on with the blocks that follow.
+type BlockEmbed interface {
+	FormatWriter
+	IsBlock() bool // Say if the embed is written as a block; if not, it is written inline like other embeds.
+}
+
+// An embedStyler is a BlockEmbed that can write the classes and styles of its block, such as an alignment, on its own
+// element.
+type embedStyler interface {
+	setStyles(classes, styles []string)
+}
+
 // A Format specifies how styling to text is applied. The Val string is what is printed in the place given by Place. Block indicates
-// if this is a block-level format.
+// if this is a block-level format. A Format placed in a Tag is left out unless its Val is a plain tag name (such as "h1").
 type Format struct {
 	Val               string      // the value to print
 	Place             FormatPlace // where this format is placed in the text
@@ -398,6 +661,13 @@ type Format struct {
 	fm                Formatter   // where this instance of a Format came from
 }
 
+// noFormat is a Formatter that applies no format at all. It is given for attributes that must not be rendered.
+type noFormat struct{}
+
+func (noFormat) Fmt() *Format { return nil }
+
+func (noFormat) HasFormat(*Op) bool { return false }
+
 // A blankOp can be used to signal any FormatWrapper formats to write the final closing wrap.
 func blankOp() *Op {
 	return &Op{"", "text", make(map[string]string)}
@@ -407,11 +677,49 @@ func blankOp() *Op {
 // "class" attribute and spaces between each class name.
 func classesList(cl []string) string {
 	if len(cl) > 0 {
-		return " class=" + strconv.Quote(strings.Join(cl, " "))
+		return " class=" + quoteAttr(strings.Join(cl, " "))
 	}
 	return ""
 }
 
+// styleAttrs returns the class and style attributes to add to an HTML element for the classes and styles, each with a
+// space before it. Attributes without any values are left out.
+func styleAttrs(classes, styles []string) string {
+	s := classesList(classes)
+	if len(styles) > 0 {
+		s += " style=" + quoteAttr(strings.Join(styles, ""))
+	}
+	return s
+}
+
+// quoteAttr escapes s for use as an HTML attribute value and surrounds it with double quotes.
+func quoteAttr(s string) string {
+	return `"` + html.EscapeString(s) + `"`
+}
+
+// textEscaper escapes the characters that are special in HTML text content.
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// A limitedReader reads up to N bytes from R. If R has more to read, ErrInputTooLarge is returned.
+type limitedReader struct {
+	R io.Reader
+	N int
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.N < 0 {
+		return 0, ErrInputTooLarge
+	}
+	if len(p) > l.N+1 {
+		p = p[:l.N+1] // Read one byte past the limit to know if there is more.
+	}
+	n, err := l.R.Read(p)
+	if l.N -= n; l.N < 0 {
+		return n - 1, ErrInputTooLarge
+	}
+	return n, err
+}
+
 // closeTag writes a complete closing tag to buf.
 func closeTag(buf *bytes.Buffer, tagName string) {
 	buf.WriteString("</")
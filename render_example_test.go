@@ -2,7 +2,7 @@ package quill_test
 
 import (
 	"fmt"
-	"quill"
+	quill "github.com/atmen-io/go-render-quill"
 )
 
 var ops = []byte(`
@@ -47,3 +47,17 @@ func Example() {
 	fmt.Println(string(html))
 	// Output: <h1>Heading1</h1><p>Hello, this is text.</p><p>And <em>here is italic </em>(and not).</p><p>And <strong>here is bold</strong></p>
 }
+
+func ExampleNewRenderer() {
+	r := quill.NewRenderer(
+		quill.WithURLPolicy(&quill.URLPolicy{LinkSchemes: []string{"https"}}),
+		quill.WithMaxOps(10000),
+	)
+	html, err := r.Render([]byte(`[{"insert":"safe","attributes":{"link":"https://example.com"}},{"insert":" and "},
+		{"insert":"unsafe","attributes":{"link":"http://example.com"}},{"insert":"\n"}]`))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(html))
+	// Output: <p><a href="https://example.com" target="_blank" rel="nofollow noopener">safe</a> and unsafe</p>
+}
@@ -2,8 +2,12 @@ package quill
 
 import (
 	"bytes"
+	"html"
+	"io"
 	"io/ioutil"
+	"os"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -37,17 +41,57 @@ func TestSimple(t *testing.T) {
 			ops:  `[{"attributes":{"strike":true},"insert":"striked"},{"insert":"\n"}]`,
 			want: "<p><s>striked</s></p>",
 		},
+		"inline code": {
+			ops:  `[{"insert":"run "},{"attributes":{"code":true},"insert":"go <test>"},{"insert":"\n"}]`,
+			want: "<p>run <code>go &lt;test&gt;</code></p>",
+		},
+		"font": {
+			ops:  `[{"attributes":{"font":"serif"},"insert":"a"},{"attributes":{"font":"monospace"},"insert":"b"},{"attributes":{"font":"comic"},"insert":"c"},{"insert":"\n"}]`,
+			want: `<p><span class="ql-font-serif">a</span><span class="ql-font-monospace">b</span>c</p>`,
+		},
+		"direction": {
+			ops:  `[{"insert":"abc"},{"attributes":{"direction":"rtl","align":"right"},"insert":"\n"},{"insert":"def"},{"attributes":{"direction":"ltr"},"insert":"\n"}]`,
+			want: `<p class="ql-align-right ql-direction-rtl">abc</p><p>def</p>`,
+		},
+		"sizes": {
+			ops:  `[{"attributes":{"size":"huge"},"insert":"a"},{"attributes":{"size":"normal"},"insert":"b"},{"attributes":{"size":"99px"},"insert":"c"},{"insert":"\n"}]`,
+			want: `<p><span class="ql-size-huge">a</span>bc</p>`,
+		},
+		"alignments": {
+			ops:  `[{"insert":"a"},{"attributes":{"align":"justify"},"insert":"\n"},{"insert":"b"},{"attributes":{"align":"left"},"insert":"\n"},{"insert":"c"},{"attributes":{"align":"middle"},"insert":"\n"}]`,
+			want: `<p class="ql-align-justify">a</p><p>b</p><p>c</p>`,
+		},
+		"indents": {
+			ops:  `[{"insert":"a"},{"attributes":{"indent":8},"insert":"\n"},{"insert":"b"},{"attributes":{"indent":9},"insert":"\n"},{"insert":"c"},{"attributes":{"indent":"x"},"insert":"\n"}]`,
+			want: `<p class="indent-8">a</p><p>b</p><p>c</p>`,
+		},
+		"scripts": {
+			ops:  `[{"attributes":{"script":"sub"},"insert":"a"},{"attributes":{"script":"super"},"insert":"b"},{"attributes":{"script":"above"},"insert":"c"},{"insert":"\n"}]`,
+			want: `<p><sub>a</sub><sup>b</sup>c</p>`,
+		},
+		"header levels": {
+			ops:  `[{"insert":"a"},{"attributes":{"header":6},"insert":"\n"},{"insert":"b"},{"attributes":{"header":9},"insert":"\n"},{"insert":"c"},{"attributes":{"header":"1><script"},"insert":"\n"}]`,
+			want: "<h6>a</h6><p>b</p><p>c</p>",
+		},
 		"list": {
 			ops:  `[{"insert":"abc "},{"attributes":{"bold":true},"insert":"bld"},{"attributes":{"list":"bullet"},"insert":"\n"}]`,
 			want: "<ul><li>abc <strong>bld</strong></li></ul>",
 		},
 		"image": {
 			ops:  `[{"insert":{"image":"source-url"}},{"insert":"\n"}]`,
-			want: `<p><img src="source-url"></p>`,
+			want: `<p><img src="source-url" loading="lazy"></p>`,
 		},
 		"image wrapped": {
 			ops:  `[{"insert":"text "},{"insert":{"image":"source-url"}},{"insert":" more text\n"}]`,
-			want: `<p>text <img src="source-url"> more text</p>`,
+			want: `<p>text <img src="source-url" loading="lazy"> more text</p>`,
+		},
+		"named color": {
+			ops:  `[{"attributes":{"color":"Red"},"insert":"red"},{"insert":"\n"}]`,
+			want: `<p><span style="color:#ff0000;">red</span></p>`,
+		},
+		"invalid color": {
+			ops:  `[{"attributes":{"color":"red;position:fixed;top:0"},"insert":"text"},{"insert":"\n"}]`,
+			want: `<p>text</p>`,
 		},
 		"background": {
 			ops:  `[{"insert":"abc "},{"attributes":{"background":"#66a3e0"},"insert":"bkg colored"},{"insert":" plain\n"}]`,
@@ -71,6 +115,58 @@ func TestSimple(t *testing.T) {
 			ops:  `[{"insert":"plain"},{"attributes":{"script":"sub"},"insert":"sub"},{"insert":"\n"}]`,
 			want: "<p>plain<sub>sub</sub></p>",
 		},
+		"inline formats on line end": {
+			ops:  `[{"insert":"a\nb","attributes":{"bold":true}},{"insert":"\n"}]`,
+			want: "<p><strong>a</strong></p><p><strong>b</strong></p>",
+		},
+		"paragraph after code": {
+			ops:  `[{"insert":"code"},{"insert":"\n","attributes":{"code-block":true}},{"insert":"plain"},{"insert":"\n"}]`,
+			want: "<pre>code\n</pre><p>plain</p>",
+		},
+		"formatted code line": {
+			ops: `[{"insert":"a"},{"insert":"\n","attributes":{"code-block":true}},{"attributes":{"bold":true},"insert":"b"},
+				{"insert":"c"},{"insert":"\n","attributes":{"code-block":true}}]`,
+			want: "<pre>a\n<strong>b</strong>c\n</pre>",
+		},
+		"image after format": {
+			ops:  `[{"insert":"x","attributes":{"bold":true}},{"insert":{"image":"a.png"}},{"insert":"\n"}]`,
+			want: `<p><strong>x</strong><img src="a.png" loading="lazy"></p>`,
+		},
+		"link continued": {
+			ops:  `[{"insert":"x","attributes":{"link":"/a","bold":true}},{"insert":{"image":"a.png"},"attributes":{"link":"/a"}},{"insert":"\n"}]`,
+			want: `<p><a href="/a" target="_blank"><strong>x</strong><img src="a.png" loading="lazy"></a></p>`,
+		},
+		"no final line feed": {
+			ops:  `[{"insert":"line1\nline2"}]`,
+			want: "<p>line1</p><p>line2</p>",
+		},
+		"escaped text": {
+			ops:  `[{"insert":"<script>alert(1)</script> & more\n<b>bold</b>"},{"insert":"\n"}]`,
+			want: "<p>&lt;script&gt;alert(1)&lt;/script&gt; &amp; more</p><p>&lt;b&gt;bold&lt;/b&gt;</p>",
+		},
+		"escaped attributes": {
+			ops: `[{"attributes":{"link":"https://x.com/\"onmouseover=\"alert(1)"},"insert":"link"},
+				{"attributes":{"size":"x\"><script>"},"insert":"big"},{"insert":"\n"}]`,
+			want: `<p><a href="https://x.com/&#34;onmouseover=&#34;alert(1)" target="_blank" rel="nofollow noopener">link</a>` +
+				`big</p>`,
+		},
+		"image object": {
+			ops:  `[{"insert":{"image":{"src":"a.png","alt":"A \"b\"","width":300}},"attributes":{"height":"200px"}},{"insert":"\n"}]`,
+			want: `<p><img src="a.png" alt="A &#34;b&#34;" width="300" height="200" loading="lazy"></p>`,
+		},
+		"image with bad dimensions": {
+			ops:  `[{"insert":{"image":"a.png"},"attributes":{"width":"50%\" onload=\"x","height":"auto"}},{"insert":"\n"}]`,
+			want: `<p><img src="a.png" loading="lazy"></p>`,
+		},
+		"image with caption": {
+			ops: `[{"insert":{"image":"a.png"},"attributes":{"alt":"a","caption":"Fig. 1 <b>","link":"https://example.com/"}},{"insert":"\n"}]`,
+			want: `<div><a href="https://example.com/" target="_blank" rel="nofollow noopener"><figure><img src="a.png" alt="a" loading="lazy">` +
+				`<figcaption>Fig. 1 &lt;b&gt;</figcaption></figure></a></div>`,
+		},
+		"escaped image": {
+			ops:  `[{"insert":{"image":"a\"b"}},{"insert":"\n"}]`,
+			want: `<p><img src="a&#34;b" loading="lazy"></p>`,
+		},
 	}
 
 	for k, tc := range cases {
@@ -87,9 +183,57 @@ func TestSimple(t *testing.T) {
 
 }
 
+func TestRenderTrusted(t *testing.T) {
+	ops := `[{"insert":"<em>html</em>\n<b>bold</b>"},{"attributes":{"link":"a\"b"},"insert":"c"},{"insert":"\n"}]`
+	want := `<p><em>html</em></p><p><b>bold</b><a href="a&#34;b" target="_blank" rel="nofollow noopener">c</a></p>`
+	got, err := RenderTrusted([]byte(ops), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("bad rendering; got: %s", got)
+	}
+}
+
+func TestRender_tagNames(t *testing.T) {
+
+	r := NewRenderer(WithCustomFormats(func(keyword string, o *Op) Formatter {
+		if keyword == "tag" {
+			return &tagFormat{tag: o.Attrs["tag"]}
+		}
+		return nil
+	}))
+
+	ops := `[{"insert":"a","attributes":{"tag":"mark"}},{"insert":"b","attributes":{"tag":"x><img src=x onerror=alert(1)><x"}},
+		{"insert":"c","attributes":{"tag":"B"}},{"insert":"\n","attributes":{"header":"1><img src=x onerror=alert(1)><h1"}}]`
+	want := `<p><mark>a</mark>bc</p>`
+
+	got, err := r.Render([]byte(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("bad rendering; got: %s", got)
+	}
+
+}
+
+// A tagFormat writes text in the tag named by the "tag" attribute.
+type tagFormat struct {
+	tag string
+}
+
+func (tf *tagFormat) Fmt() *Format {
+	return &Format{Val: tf.tag, Place: Tag}
+}
+
+func (tf *tagFormat) HasFormat(o *Op) bool {
+	return o.Attrs["tag"] == tf.tag
+}
+
 func TestRender(t *testing.T) {
 
-	pairNames := []string{"ops1", "nested", "ordering", "list1", "list2", "list3", "list4", "indent", "code1", "code2"}
+	pairNames := []string{"ops1", "nested", "ordering", "list1", "list2", "list3", "list4", "indent", "code1", "code2", "embeds"}
 
 	for _, n := range pairNames {
 		t.Run(n, func(t *testing.T) {
@@ -118,6 +262,197 @@ func TestRender(t *testing.T) {
 
 }
 
+func TestRenderTo(t *testing.T) {
+
+	pairNames := []string{"ops1", "nested", "ordering", "list1", "list2", "list3", "list4", "indent", "code1", "code2", "embeds"}
+
+	for _, n := range pairNames {
+		t.Run(n, func(t *testing.T) {
+
+			f, err := os.Open("./testdata/" + n + ".json")
+			if err != nil {
+				t.Fatalf("could not open %s.json; %s", n, err)
+			}
+			defer f.Close()
+
+			html, err := ioutil.ReadFile("./testdata/" + n + ".html")
+			if err != nil {
+				t.Fatalf("could not read %s.html; %s", n, err)
+			}
+
+			var buf bytes.Buffer
+			if err = RenderTo(&buf, f); err != nil {
+				t.Errorf("error rendering; %v", err)
+			}
+
+			if !bytes.Equal(html, buf.Bytes()) {
+				t.Errorf("bad rendering %s:\nwanted: \n%s\ngot: \n%s", n, html, buf.Bytes())
+			}
+
+		})
+	}
+
+}
+
+// A writeCounter counts the writes made to it.
+type writeCounter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (wc *writeCounter) Write(p []byte) (int, error) {
+	wc.writes++
+	return wc.Buffer.Write(p)
+}
+
+func TestRenderTo_streaming(t *testing.T) {
+
+	const lines = 1000
+
+	var in bytes.Buffer
+	in.WriteByte('[')
+	for i := 0; i < lines; i++ {
+		if i > 0 {
+			in.WriteByte(',')
+		}
+		in.WriteString(`{"insert":"line `)
+		in.WriteString(strconv.Itoa(i))
+		in.WriteString(`\n"}`)
+	}
+	in.WriteByte(']')
+
+	want, err := Render(in.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out writeCounter
+	if err = RenderTo(&out, &in); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(want, out.Bytes()) {
+		t.Errorf("streamed rendering differs")
+	}
+	if out.writes != lines {
+		t.Errorf("expected a write for each of %d blocks; got %d writes", lines, out.writes)
+	}
+
+}
+
+func TestRenderTo_errors(t *testing.T) {
+
+	cases := map[string]struct {
+		r   *Renderer
+		in  string
+		err error
+	}{
+		"not an array": {defaultRenderer, `{"insert":"x"}`, nil},
+		"bad op":       {defaultRenderer, `[{"insert":"x\n"},{"attributes":{}}]`, nil},
+		"unterminated": {defaultRenderer, `[{"insert":"x\n"}`, nil},
+		"too large":    {NewRenderer(WithMaxInputSize(20)), `[{"insert":"x\n"},{"insert":"y\n"}]`, ErrInputTooLarge},
+		"too many ops": {NewRenderer(WithMaxOps(1)), `[{"insert":"x\n"},{"insert":"y\n"}]`, ErrTooManyOps},
+	}
+
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := tc.r.RenderTo(&buf, strings.NewReader(tc.in))
+			if err == nil {
+				t.Fatalf("no error")
+			}
+			if tc.err != nil && err != tc.err {
+				t.Errorf("wrong error: %v", err)
+			}
+		})
+	}
+
+	// What was rendered before an error is written out.
+	var buf bytes.Buffer
+	RenderTo(&buf, strings.NewReader(`[{"insert":"x\n"},{"insert":1}]`))
+	if buf.String() != "<p>x</p>" {
+		t.Errorf("rendered output not written before error; got %q", buf.String())
+	}
+
+}
+
+// calloutFormat is a custom embed that is a block of its own unless it is marked as inline.
+type calloutFormat struct {
+	text   string
+	inline bool
+}
+
+func (*calloutFormat) Fmt() *Format { return nil }
+
+func (*calloutFormat) HasFormat(o *Op) bool { return o.Type == "callout" }
+
+func (cf *calloutFormat) Write(buf io.Writer) {
+	io.WriteString(buf, "<aside>"+html.EscapeString(cf.text)+"</aside>")
+}
+
+func (cf *calloutFormat) IsBlock() bool { return !cf.inline }
+
+func TestBlockEmbed(t *testing.T) {
+
+	r := NewRenderer(WithFormat("callout", func(o *Op, _ *Renderer) Formatter {
+		return &calloutFormat{text: o.Data, inline: o.HasAttr("inline")}
+	}))
+
+	cases := map[string]struct {
+		ops  string
+		want string
+	}{
+		"between paragraphs": {
+			ops:  `[{"insert":"a\n"},{"insert":{"callout":"<note>"}},{"insert":"\nb\n"}]`,
+			want: "<p>a</p><aside>&lt;note&gt;</aside><p>b</p>",
+		},
+		"splits a line": {
+			ops:  `[{"insert":"a","attributes":{"bold":true}},{"insert":{"callout":"x"}},{"insert":"b"},{"insert":"\n","attributes":{"header":2}}]`,
+			want: "<h2><strong>a</strong></h2><aside>x</aside><h2>b</h2>",
+		},
+		"inline": {
+			ops:  `[{"insert":"a"},{"insert":{"callout":"x"},"attributes":{"inline":true}},{"insert":"b\n"}]`,
+			want: "<p>a<aside>x</aside>b</p>",
+		},
+		"in a list": {
+			ops: `[{"insert":"one"},{"insert":"\n","attributes":{"list":"ordered"}},{"insert":{"callout":"x"}},
+				{"insert":"\n","attributes":{"list":"ordered"}},{"insert":"two"},{"insert":"\n","attributes":{"list":"ordered"}}]`,
+			want: "<ol><li>one</li></ol><aside>x</aside><ol><li>two</li></ol>",
+		},
+		"inline formats": {
+			ops:  `[{"insert":{"callout":"x"},"attributes":{"bold":true,"link":"https://x.com"}},{"insert":{"divider":true},"attributes":{"italic":true}},{"insert":"\n"}]`,
+			want: `<a href="https://x.com" target="_blank" rel="nofollow noopener"><aside>x</aside></a><hr>`,
+		},
+		"aligned line": {
+			ops: `[{"insert":{"divider":true}},{"insert":"\n","attributes":{"align":"center","direction":"rtl","header":1}},` +
+				`{"insert":{"video":"https://youtu.be/abc"}},{"insert":"\n","attributes":{"align":"right"}}]`,
+			want: `<hr class="ql-align-center ql-direction-rtl"><iframe class="ql-video ql-align-right" frameborder="0" allowfullscreen="true" ` +
+				`src="https://www.youtube.com/embed/abc" sandbox="` + videoSandbox + `" allow="` + videoAllow + `"></iframe>`,
+		},
+		"aligned line with text": {
+			ops:  `[{"insert":"a"},{"insert":{"divider":true}},{"insert":"b"},{"insert":"\n","attributes":{"align":"center"}}]`,
+			want: `<p class="ql-align-center">a</p><hr><p class="ql-align-center">b</p>`,
+		},
+		"after an empty line": {
+			ops:  `[{"insert":"a\n\n"},{"insert":{"divider":true}},{"insert":"\n\n"}]`,
+			want: "<p>a</p><p><br></p><hr><p><br></p>",
+		},
+	}
+
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			got, err := r.Render([]byte(tc.ops))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("bad rendering\ngot:  %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+
+}
+
 func TestClassesList(t *testing.T) {
 	cases := []struct {
 		classes []string
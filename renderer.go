@@ -0,0 +1,132 @@
+package quill
+
+import "errors"
+
+// A Renderer renders Deltas with a fixed set of options. Create one with NewRenderer once and reuse it; a Renderer is
+// safe for concurrent use by multiple goroutines.
+type Renderer struct {
+	customFormats func(string, *Op) Formatter
+	formats       *Registry
+	urls          URLPolicy
+	colors        ColorPolicy
+	trusted       bool // write the text of inserts without escaping it
+	maxSize       int  // the maximum size of the input in bytes (0 means no limit)
+	maxOps        int  // the maximum number of ops in the input (0 means no limit)
+	mdFallback    MarkdownFallback
+	textWidth     int  // the column width of plain text (0 means no wrapping)
+	nestedLists   bool // write indented list items inside of the item before them
+	version       QuillVersion
+	highlight     HighlightStyle
+	styles        *InlineStyles // the inline styles written in place of Quill's classes, if set
+	email         bool          // write HTML that email clients can show
+	classes       ClassMapper   // names the classes of formats, if set
+}
+
+// An Option sets up a Renderer.
+type Option func(*Renderer)
+
+// NewRenderer returns a Renderer set up with the given options. Options are applied in order, so if the same setting
+// is given more than once the last one is used.
+func NewRenderer(opts ...Option) *Renderer {
+	r := &Renderer{formats: NewRegistry()}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// defaultRenderer is used by the package-level render functions.
+var defaultRenderer = NewRenderer()
+
+var (
+	// ErrInputTooLarge is returned when the input is larger than the limit set with WithMaxInputSize.
+	ErrInputTooLarge = errors.New("quill: input exceeds the maximum size")
+
+	// ErrTooManyOps is returned when the input has more ops than the limit set with WithMaxOps.
+	ErrTooManyOps = errors.New("quill: input exceeds the maximum number of ops")
+)
+
+// WithCustomFormats sets a function that may provide a Formatter to customize the way certain kinds of inserts are
+// rendered. The function is called with the insert type of each op and then with each of its attributes; if it returns
+// nil, the Formatter from the Renderer's Registry is used. The function must be safe for concurrent use.
+func WithCustomFormats(customFormats func(string, *Op) Formatter) Option {
+	return func(r *Renderer) {
+		r.customFormats = customFormats
+	}
+}
+
+// WithRegistry sets the formats that the Renderer uses. The Renderer keeps a copy of the Registry. If reg is nil, the
+// built-in formats (those of NewRegistry) are used.
+func WithRegistry(reg *Registry) Option {
+	return func(r *Renderer) {
+		if reg == nil {
+			r.formats = NewRegistry()
+			return
+		}
+		r.formats = reg.Clone()
+	}
+}
+
+// WithFormat registers a format on the Renderer's Registry, adding it or replacing a built-in format.
+func WithFormat(name string, f FormatFactory) Option {
+	return func(r *Renderer) {
+		r.formats.Register(name, f)
+	}
+}
+
+// WithoutFormats unregisters the named formats from the Renderer's Registry.
+func WithoutFormats(names ...string) Option {
+	return func(r *Renderer) {
+		for _, name := range names {
+			r.formats.Unregister(name)
+		}
+	}
+}
+
+// WithURLPolicy sets the policy for the URLs of links and images. The policy is copied, but it must not be modified
+// while the Renderer is in use. If p is nil, the default policy (the zero URLPolicy) is used.
+func WithURLPolicy(p *URLPolicy) Option {
+	return func(r *Renderer) {
+		if p == nil {
+			r.urls = URLPolicy{}
+			return
+		}
+		r.urls = *p
+	}
+}
+
+// WithColorPolicy sets the policy for the values of the color and background attributes. If p is nil, the default
+// policy (the zero ColorPolicy) is used.
+func WithColorPolicy(p *ColorPolicy) Option {
+	return func(r *Renderer) {
+		if p == nil {
+			r.colors = ColorPolicy{}
+			return
+		}
+		r.colors = *p
+	}
+}
+
+// WithTrustedInput makes the Renderer write the text of inserts without HTML-escaping it, so any markup in the Delta
+// is kept as is. Use it only for Deltas that come from a trusted source. Attribute values are still quoted properly.
+func WithTrustedInput() Option {
+	return func(r *Renderer) {
+		r.trusted = true
+	}
+}
+
+// WithMaxInputSize limits the size in bytes of the Deltas the Renderer accepts. Larger input is rejected with
+// ErrInputTooLarge. A limit of 0 means no limit.
+func WithMaxInputSize(n int) Option {
+	return func(r *Renderer) {
+		r.maxSize = n
+	}
+}
+
+// WithMaxOps limits the number of ops in the Deltas the Renderer accepts. Input with more ops is rejected with
+// ErrTooManyOps. A limit of 0 means no limit.
+func WithMaxOps(n int) Option {
+	return func(r *Renderer) {
+		r.maxOps = n
+	}
+}
@@ -0,0 +1,98 @@
+package quill
+
+import (
+	"net/url"
+	"sync"
+	"testing"
+)
+
+func TestRenderer_options(t *testing.T) {
+
+	base, _ := url.Parse("https://example.com/")
+	colors, err := NewColorPolicy("#000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRenderer(
+		WithURLPolicy(&URLPolicy{Base: base}),
+		WithColorPolicy(colors),
+		WithCustomFormats(func(keyword string, o *Op) Formatter {
+			if keyword == "bold" {
+				return new(italicFormat)
+			}
+			return nil
+		}),
+	)
+
+	ops := `[{"attributes":{"link":"a","color":"red"},"insert":"x"},{"attributes":{"bold":true,"color":"black"},"insert":"y"},{"insert":"\n"}]`
+	want := `<p><a href="https://example.com/a" target="_blank" rel="nofollow noopener">x</a>` +
+		`<em><span style="color:#000000;">y</span></em></p>`
+
+	got, err := r.Render([]byte(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("bad rendering; got: %s", got)
+	}
+
+	got, err = NewRenderer(WithTrustedInput()).Render([]byte(`[{"insert":"<b>x</b>\n"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "<p><b>x</b></p>" {
+		t.Errorf("bad trusted rendering; got: %s", got)
+	}
+
+	// Nil policies put back the defaults.
+	r = NewRenderer(WithURLPolicy(&URLPolicy{Base: base}), WithColorPolicy(colors), WithURLPolicy(nil), WithColorPolicy(nil))
+	got, err = r.Render([]byte(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := defaultRenderer.Render([]byte(ops)); string(got) != string(want) {
+		t.Errorf("bad rendering with nil policies; got: %s", got)
+	}
+
+}
+
+func TestRenderer_limits(t *testing.T) {
+
+	ops := []byte(`[{"insert":"a"},{"insert":"b"},{"insert":"\n"}]`)
+
+	if _, err := NewRenderer(WithMaxInputSize(10)).Render(ops); err != ErrInputTooLarge {
+		t.Errorf("expected ErrInputTooLarge; got %v", err)
+	}
+	if _, err := NewRenderer(WithMaxOps(2)).Render(ops); err != ErrTooManyOps {
+		t.Errorf("expected ErrTooManyOps; got %v", err)
+	}
+	if _, err := NewRenderer(WithMaxInputSize(len(ops)), WithMaxOps(3)).Render(ops); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+}
+
+func TestRenderer_concurrent(t *testing.T) {
+
+	r := NewRenderer(WithURLPolicy(&URLPolicy{ImagePlaceholder: "/x.png"}))
+	ops := []byte(`[{"insert":"a "},{"insert":{"image":"javascript:x"}},{"attributes":{"bold":true},"insert":"b"},{"attributes":{"list":"bullet"},"insert":"\n"}]`)
+	want := `<ul><li>a <img src="/x.png" loading="lazy"><strong>b</strong></li></ul>`
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				got, err := r.Render(ops)
+				if err != nil || string(got) != want {
+					t.Errorf("bad rendering; got %s (%v)", got, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+}
@@ -0,0 +1,234 @@
+package quill
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithTextWidth sets the column width at which RenderText wraps lines. Wrapped list items and quotes keep their
+// hanging indent. A width of 0 (the default) means that lines are not wrapped.
+func WithTextWidth(width int) Option {
+	return func(r *Renderer) {
+		r.textWidth = width
+	}
+}
+
+// RenderText takes a Delta array of insert operations and returns it as plain text using the built-in settings.
+func RenderText(ops []byte) ([]byte, error) {
+	return defaultRenderer.RenderText(ops)
+}
+
+// RenderText takes a Delta array of insert operations and returns it as readable plain text.
+//
+// Paragraphs are separated by blank lines, quotes are prefixed with "> ", ordered list items are numbered and bullet
+// items are marked according to their indent, and code blocks are indented by four spaces. Links are followed by their
+// URL in parentheses, images are written as their alt text or else their URL, videos are written as their URL,
+// formulas are written as their LaTeX source, and dividers are written as "---". If a width is set with WithTextWidth,
+// lines are wrapped at that width.
+func (r *Renderer) RenderText(ops []byte) ([]byte, error) {
+	doc, err := r.Parse(ops)
+	if err != nil {
+		return nil, err
+	}
+	return r.RenderDocumentText(doc), nil
+}
+
+// RenderDocumentText returns the Document as plain text. See RenderText.
+func (r *Renderer) RenderDocumentText(doc *Document) []byte {
+
+	w := textWriter{r: r}
+
+	for _, g := range doc.Groups() {
+		mark := w.buf.Len()
+		if mark > 0 {
+			w.buf.WriteByte('\n') // Separate groups with a blank line.
+		}
+		switch g.Kind {
+		case ListGroup:
+			w.writeList(g.Blocks)
+		case CodeGroup:
+			for _, b := range g.Blocks {
+				w.writeLine("    ", b.Text())
+			}
+		default:
+			b := g.Blocks[0]
+			if text := w.blockText(b); text != "" {
+				prefix := ""
+				if b.HasAttr("blockquote") {
+					prefix = "> "
+				}
+				w.writeWrapped(text, prefix, prefix)
+			}
+		}
+		if w.buf.Len() <= mark+1 {
+			w.buf.Truncate(mark) // Nothing was written.
+		}
+	}
+
+	return w.buf.Bytes()
+
+}
+
+// A textWriter writes a Document as plain text.
+type textWriter struct {
+	r   *Renderer
+	buf bytes.Buffer
+}
+
+// textBullets are the markers of bullet list items at each indent level (repeating for deeper levels).
+var textBullets = []string{"- ", "* ", "+ "}
+
+// writeList writes the items of a list, indenting nested items under the text of their parent item.
+func (w *textWriter) writeList(items []*Block) {
+
+	type level struct {
+		lType  string
+		num    int
+		indent int // the width of the indent before the marker
+		width  int // the width of the marker
+	}
+
+	var levels []level
+
+	for _, b := range items {
+
+		depth, _ := strconv.Atoi(b.Attrs["indent"])
+		if depth < 0 {
+			depth = 0
+		} else if depth > len(levels) {
+			depth = len(levels)
+		}
+
+		lType := b.Attrs["list"]
+		if depth < len(levels) {
+			levels = levels[:depth+1]
+			if levels[depth].lType != lType {
+				levels[depth] = level{lType: lType, indent: levels[depth].indent}
+			}
+		} else {
+			lvl := level{lType: lType}
+			if depth > 0 {
+				lvl.indent = levels[depth-1].indent + levels[depth-1].width
+			}
+			levels = append(levels, lvl)
+		}
+
+		lvl := &levels[depth]
+		var marker string
+		switch lType {
+		case "ordered":
+			lvl.num++
+			marker = strconv.Itoa(lvl.num) + ". "
+		case "checked":
+			marker = "[x] "
+		case "unchecked":
+			marker = "[ ] "
+		default:
+			marker = textBullets[depth%len(textBullets)]
+		}
+		lvl.width = len(marker)
+
+		indent := strings.Repeat(" ", lvl.indent)
+		w.writeWrapped(w.blockText(b), indent+marker, indent+strings.Repeat(" ", len(marker)))
+
+	}
+
+}
+
+// blockText returns the text of a block with links followed by their URLs and images replaced by their alt text or URL.
+func (w *textWriter) blockText(b *Block) string {
+
+	var sb strings.Builder
+	var link, linkText string // the URL of the link being written and its text so far
+
+	endLink := func() {
+		if link != "" && strings.TrimSpace(linkText) != link {
+			sb.WriteString(" (" + link + ")")
+		}
+		link, linkText = "", ""
+	}
+
+	for _, o := range b.Inlines {
+
+		href := ""
+		if o.HasAttr("link") && w.r.formats.Lookup("link") != nil {
+			href, _ = w.r.urls.Resolve(o.Attrs["link"], LinkURL)
+		}
+		if href != link {
+			endLink()
+			link = href
+		}
+
+		var text string
+		switch {
+		case o.Type == "text":
+			text = o.Data
+		case w.r.formats.Lookup(o.Type) == nil:
+		case o.Type == "image":
+			if text = o.Attrs["alt"]; text == "" {
+				text, _ = w.r.urls.Resolve(o.Data, ImageURL)
+				if strings.HasPrefix(strings.ToLower(text), "data:") {
+					text = "" // The content of the image is no use as text.
+				}
+			}
+		case o.Type == "video":
+			text, _ = w.r.urls.Resolve(o.Data, LinkURL)
+		case o.Type == "formula":
+			text = o.Data
+		case o.Type == "divider":
+			text = "---"
+		}
+
+		sb.WriteString(text)
+		if link != "" {
+			linkText += text
+		}
+
+	}
+	endLink()
+
+	return strings.TrimSpace(sb.String())
+
+}
+
+// writeLine writes a single line with the prefix.
+func (w *textWriter) writeLine(prefix, line string) {
+	w.buf.WriteString(strings.TrimRight(prefix+line, " "))
+	w.buf.WriteByte('\n')
+}
+
+// writeWrapped writes text, wrapping it at the width of the Renderer if there is one. The first line starts with first,
+// and the lines wrapped after it start with rest.
+func (w *textWriter) writeWrapped(text, first, rest string) {
+
+	width := w.r.textWidth
+	if width <= 0 {
+		w.writeLine(first, text)
+		return
+	}
+
+	prefix := first
+	var line strings.Builder
+	lineLen := 0
+
+	for _, word := range strings.Fields(text) {
+		n := utf8.RuneCountInString(word)
+		if lineLen > 0 && utf8.RuneCountInString(prefix)+lineLen+1+n > width {
+			w.writeLine(prefix, line.String())
+			prefix = rest
+			line.Reset()
+			lineLen = 0
+		}
+		if lineLen > 0 {
+			line.WriteByte(' ')
+			lineLen++
+		}
+		line.WriteString(word) // A word longer than the width is left on a line by itself.
+		lineLen += n
+	}
+
+	w.writeLine(prefix, line.String())
+
+}
@@ -0,0 +1,90 @@
+package quill
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestRenderText(t *testing.T) {
+
+	ops, err := ioutil.ReadFile("./testdata/list4.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		r    *Renderer
+		ops  string
+		want string
+	}{
+		"paragraphs": {
+			ops:  `[{"insert":"Title"},{"insert":"\n","attributes":{"header":1}},{"insert":"one "},{"insert":"bold","attributes":{"bold":true}},{"insert":"\n\n\ntwo\n"}]`,
+			want: "Title\n\none bold\n\ntwo\n",
+		},
+		"nested list": {
+			ops:  string(ops),
+			want: "1. 1(ol)-1\n2. 1(ol)-2 bold\n- 1(ul)-3 italic\n1. 1(ol)-4\n   1. under-ital_before 2(ol)-1\n      1. 3(ol)-1\n      2. 3(ol)-2\n      + 3(ul)-3\n   * 2(ul)-2\n",
+		},
+		"wrapped list": {
+			r: NewRenderer(WithTextWidth(16)),
+			ops: `[{"insert":"the first item is long"},{"insert":"\n","attributes":{"list":"ordered"}},
+				{"insert":"a nested bullet item"},{"insert":"\n","attributes":{"list":"bullet","indent":1}}]`,
+			want: "1. the first\n   item is long\n   * a nested\n     bullet item\n",
+		},
+		"wrapped quote": {
+			r:    NewRenderer(WithTextWidth(10)),
+			ops:  `[{"insert":"to be or not to be"},{"insert":"\n","attributes":{"blockquote":true}}]`,
+			want: "> to be or\n> not to\n> be\n",
+		},
+		"long word": {
+			r:    NewRenderer(WithTextWidth(5)),
+			ops:  `[{"insert":"a https://example.com b\n"}]`,
+			want: "a\nhttps://example.com\nb\n",
+		},
+		"code": {
+			r:    NewRenderer(WithTextWidth(5)),
+			ops:  `[{"insert":"if x {"},{"insert":"\n","attributes":{"code-block":true}},{"insert":"\treturn too long"},{"insert":"\n","attributes":{"code-block":true}},{"insert":"}"},{"insert":"\n","attributes":{"code-block":true}}]`,
+			want: "    if x {\n    \treturn too long\n    }\n",
+		},
+		"links and images": {
+			ops: `[{"insert":"see "},{"insert":"our site","attributes":{"link":"https://example.com"}},{"insert":" or "},
+				{"insert":"https://example.com","attributes":{"link":"https://example.com"}},{"insert":" "},
+				{"insert":{"image":"https://example.com/a.png"}},{"insert":" "},{"insert":{"image":"b.png"},"attributes":{"alt":"A cat"}},
+				{"insert":" "},{"insert":"bad","attributes":{"link":"javascript:x"}},{"insert":"\n"}]`,
+			want: "see our site (https://example.com) or https://example.com https://example.com/a.png A cat bad\n",
+		},
+		"task list": {
+			ops:  `[{"insert":"done"},{"insert":"\n","attributes":{"list":"checked"}},{"insert":"todo"},{"insert":"\n","attributes":{"list":"unchecked"}}]`,
+			want: "[x] done\n[ ] todo\n",
+		},
+		"divider": {
+			ops:  `[{"insert":"a\n"},{"insert":{"divider":true}},{"insert":"\nb\n"}]`,
+			want: "a\n\n---\n\nb\n",
+		},
+		"divider sharing a line": {
+			ops:  `[{"insert":{"divider":true}},{"insert":"hi\n"}]`,
+			want: "---\n\nhi\n",
+		},
+		"dividers sharing a line": {
+			ops:  `[{"insert":"a"},{"insert":{"divider":true}},{"insert":{"divider":true}},{"insert":"hi\n"}]`,
+			want: "a\n\n---\n\n---\n\nhi\n",
+		},
+	}
+
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			r := tc.r
+			if r == nil {
+				r = defaultRenderer
+			}
+			got, err := r.RenderText([]byte(tc.ops))
+			if err != nil {
+				t.Fatalf("%s", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("bad rendering; got: %q", got)
+			}
+		})
+	}
+
+}
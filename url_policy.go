@@ -0,0 +1,146 @@
+package quill
+
+import (
+	"net/url"
+	"strings"
+)
+
+// A URLUse says where in the output a URL is written.
+type URLUse uint8
+
+const (
+	LinkURL  URLUse = iota // the href of a link
+	ImageURL               // the src of an image
+	VideoURL               // the src of the iframe of a video
+)
+
+// A URLPolicy decides which URLs of links, images and videos may be written out and how they are written.
+//
+// The zero value is ready to use: it allows only the schemes that Quill itself allows (http, https, mailto and tel for
+// links; http, https and data with an image media type for images) as well as relative URLs, which are left as they are.
+// Videos are embedded only from the hosts of well-known video providers.
+type URLPolicy struct {
+	// LinkSchemes lists the allowed schemes (such as "https") of links. If nil, DefaultLinkSchemes is used.
+	LinkSchemes []string
+
+	// ImageSchemes lists the allowed schemes of images. If nil, DefaultImageSchemes is used.
+	ImageSchemes []string
+
+	// VideoHosts lists the hosts (such as "player.vimeo.com") from which videos may be embedded. The URLs of the pages of
+	// videos on YouTube, Vimeo and other known providers are turned into embed URLs before the host is checked. Videos
+	// from other hosts are written as links. If nil, DefaultVideoHosts is used.
+	VideoHosts []string
+
+	// Base, if set, is the URL against which relative URLs are resolved.
+	Base *url.URL
+
+	// Rewrite, if set, is called with every allowed URL (after it is resolved against Base) and returns the URL to
+	// write instead, which may be u itself. If Rewrite returns nil, the URL is blocked.
+	Rewrite func(u *url.URL, use URLUse) *url.URL
+
+	// ImagePlaceholder is the src written for an image whose URL is blocked. If it is blank, blocked images are
+	// dropped. Links with blocked URLs are always dropped, leaving only their text.
+	ImagePlaceholder string
+}
+
+var (
+	// DefaultLinkSchemes lists the URL schemes allowed for links by default.
+	DefaultLinkSchemes = []string{"http", "https", "mailto", "tel"}
+
+	// DefaultImageSchemes lists the URL schemes allowed for images by default.
+	DefaultImageSchemes = []string{"http", "https", "data"}
+)
+
+// Resolve checks the raw URL given in a Delta against the policy. If the URL is allowed, Resolve returns the URL to write
+// out and true. Otherwise, it returns false.
+func (p *URLPolicy) Resolve(raw string, use URLUse) (string, bool) {
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+
+	changed := false
+	if !u.IsAbs() && p.Base != nil {
+		u = p.Base.ResolveReference(u)
+		changed = true
+	}
+
+	if use == VideoURL {
+		// Videos are embedded only from the allowed hosts, over HTTP or HTTPS.
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return "", false
+		}
+		e := embedVideoURL(u)
+		if e == nil {
+			return "", false
+		}
+		if e != u {
+			u, changed = e, true
+		}
+		if !p.videoHostAllowed(u) {
+			return "", false
+		}
+	} else if u.Scheme != "" && !p.schemeAllowed(u, use) {
+		return "", false
+	}
+
+	if p.Rewrite != nil {
+		if u = p.Rewrite(u, use); u == nil {
+			return "", false
+		}
+		changed = true
+	}
+
+	if changed {
+		return u.String(), true
+	}
+	return raw, true
+
+}
+
+// schemeAllowed says if the scheme of the absolute URL u is allowed for the given use.
+func (p *URLPolicy) schemeAllowed(u *url.URL, use URLUse) bool {
+
+	schemes := p.LinkSchemes
+	if use == ImageURL {
+		schemes = p.ImageSchemes
+		if schemes == nil {
+			schemes = DefaultImageSchemes
+		}
+	} else if schemes == nil {
+		schemes = DefaultLinkSchemes
+	}
+
+	// Data URLs may contain only images, which is what makes them safe to embed.
+	if u.Scheme == "data" && (use != ImageURL || !strings.HasPrefix(strings.ToLower(u.Opaque), "image/")) {
+		return false
+	}
+
+	for _, s := range schemes {
+		if strings.EqualFold(s, u.Scheme) {
+			return true
+		}
+	}
+	return false
+
+}
+
+// Formatter gives the formats for links, images and videos that follow the policy. It can be given to RenderExtended as the
+// function providing custom formats, though usually the policy is set on a Renderer with WithURLPolicy.
+func (p *URLPolicy) Formatter(keyword string, o *Op) Formatter {
+	switch keyword {
+	case "link":
+		return newLinkFormat(o, p)
+	case "image":
+		return newImageFormat(o, p)
+	case "video":
+		return newVideoFormat(o, p)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package quill
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestURLPolicy_Resolve(t *testing.T) {
+
+	base, _ := url.Parse("https://example.com/docs/")
+
+	cases := []struct {
+		policy URLPolicy
+		raw    string
+		use    URLUse
+		want   string
+		ok     bool
+	}{
+		{URLPolicy{}, "https://widerwebs.com", LinkURL, "https://widerwebs.com", true},
+		{URLPolicy{}, "  /relative/path ", LinkURL, "/relative/path", true},
+		{URLPolicy{}, "mailto:someone@example.com", LinkURL, "mailto:someone@example.com", true},
+		{URLPolicy{}, "javascript:alert(1)", LinkURL, "", false},
+		{URLPolicy{}, "JavaScript:alert(1)", LinkURL, "", false},
+		{URLPolicy{}, " javascript:alert(1)", LinkURL, "", false},
+		{URLPolicy{}, "java\tscript:alert(1)", LinkURL, "", false},
+		{URLPolicy{}, "data:text/html,<script>", LinkURL, "", false},
+		{URLPolicy{}, "data:text/html,<script>", ImageURL, "", false},
+		{URLPolicy{}, "data:image/png;base64,AAAA", ImageURL, "data:image/png;base64,AAAA", true},
+		{URLPolicy{}, "mailto:someone@example.com", ImageURL, "", false},
+		{URLPolicy{}, "", LinkURL, "", false},
+		{URLPolicy{LinkSchemes: []string{"https"}}, "http://example.com", LinkURL, "", false},
+		{URLPolicy{Base: base}, "page.html", LinkURL, "https://example.com/docs/page.html", true},
+		{URLPolicy{Base: base}, "/root", ImageURL, "https://example.com/root", true},
+		{URLPolicy{Base: base}, "http://other.com/x", LinkURL, "http://other.com/x", true},
+		{
+			URLPolicy{Rewrite: func(u *url.URL, use URLUse) *url.URL {
+				if use == ImageURL {
+					u.Host = "cdn.example.com"
+				}
+				return u
+			}},
+			"https://example.com/img.png", ImageURL, "https://cdn.example.com/img.png", true,
+		},
+		{
+			URLPolicy{Rewrite: func(u *url.URL, use URLUse) *url.URL { return nil }},
+			"https://example.com/", LinkURL, "", false,
+		},
+	}
+
+	for i, tc := range cases {
+		got, ok := tc.policy.Resolve(tc.raw, tc.use)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("(index %d) resolving %q; got (%q, %v)", i, tc.raw, got, ok)
+		}
+	}
+
+}
+
+func TestURLPolicy_Formatter(t *testing.T) {
+
+	base, _ := url.Parse("https://example.com/")
+
+	cases := map[string]struct {
+		policy URLPolicy
+		ops    string
+		want   string
+	}{
+		"blocked link keeps text": {
+			ops:  `[{"attributes":{"link":"javascript:alert(1)"},"insert":"click"},{"insert":"\n"}]`,
+			want: "<p>click</p>",
+		},
+		"blocked image dropped": {
+			ops:  `[{"insert":"a"},{"insert":{"image":"javascript:alert(1)"}},{"insert":"b\n"}]`,
+			want: "<p>ab</p>",
+		},
+		"blocked image placeholder": {
+			policy: URLPolicy{ImagePlaceholder: "/blocked.png"},
+			ops:    `[{"insert":{"image":"vbscript:x"}},{"insert":"\n"}]`,
+			want:   `<p><img src="/blocked.png" loading="lazy"></p>`,
+		},
+		"resolved link": {
+			policy: URLPolicy{Base: base},
+			ops:    `[{"attributes":{"link":"about"},"insert":"about"},{"insert":"\n"}]`,
+			want:   `<p><a href="https://example.com/about" target="_blank" rel="nofollow noopener">about</a></p>`,
+		},
+	}
+
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			got, err := RenderExtended([]byte(tc.ops), tc.policy.Formatter)
+			if err != nil {
+				t.Fatalf("%s", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("bad rendering; got: %s", got)
+			}
+		})
+	}
+
+}
@@ -0,0 +1,193 @@
+package quill
+
+import (
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DefaultVideoHosts lists the hosts whose videos may be embedded by default: those of the video providers whose URLs
+// are recognized (YouTube, Vimeo, Dailymotion, Wistia and Loom) after the URLs are turned into embed URLs.
+var DefaultVideoHosts = []string{
+	"www.youtube.com",
+	"www.youtube-nocookie.com",
+	"player.vimeo.com",
+	"www.dailymotion.com",
+	"fast.wistia.net",
+	"www.loom.com",
+}
+
+// videoSandbox and videoAllow are the sandbox and allow attributes of the iframes of videos, which give the players
+// what they need and no more.
+const (
+	videoSandbox = "allow-scripts allow-same-origin allow-presentation allow-popups"
+	videoAllow   = "accelerometer; autoplay; clipboard-write; encrypted-media; gyroscope; picture-in-picture; fullscreen"
+)
+
+// videoProviders recognize the URLs of the pages of videos on a known host and give the embed URL of each video. The
+// first submatch of the pattern is the ID of the video.
+var videoProviders = []struct {
+	hosts   []string
+	pattern *regexp.Regexp
+	embed   string // the embed URL, with the ID to be added
+}{
+	{
+		hosts:   []string{"youtube.com", "www.youtube.com", "m.youtube.com", "music.youtube.com"},
+		pattern: regexp.MustCompile(`^/(?:watch|(?:embed|shorts|live|v)/([\w-]+))/?$`),
+		embed:   "https://www.youtube.com/embed/",
+	},
+	{
+		hosts:   []string{"youtu.be"},
+		pattern: regexp.MustCompile(`^/([\w-]+)/?$`),
+		embed:   "https://www.youtube.com/embed/",
+	},
+	{
+		hosts:   []string{"www.youtube-nocookie.com", "youtube-nocookie.com"},
+		pattern: regexp.MustCompile(`^/embed/([\w-]+)/?$`),
+		embed:   "https://www.youtube-nocookie.com/embed/",
+	},
+	{
+		hosts:   []string{"vimeo.com", "www.vimeo.com"},
+		pattern: regexp.MustCompile(`^/(?:channels/[\w-]+/|groups/[\w-]+/videos/)?(\d+)/?$`),
+		embed:   "https://player.vimeo.com/video/",
+	},
+	{
+		hosts:   []string{"player.vimeo.com"},
+		pattern: regexp.MustCompile(`^/video/(\d+)/?$`),
+		embed:   "https://player.vimeo.com/video/",
+	},
+	{
+		hosts:   []string{"dailymotion.com", "www.dailymotion.com"},
+		pattern: regexp.MustCompile(`^/(?:embed/)?video/([a-zA-Z0-9]+)/?$`),
+		embed:   "https://www.dailymotion.com/embed/video/",
+	},
+	{
+		hosts:   []string{"dai.ly"},
+		pattern: regexp.MustCompile(`^/([a-zA-Z0-9]+)/?$`),
+		embed:   "https://www.dailymotion.com/embed/video/",
+	},
+	{
+		hosts:   []string{"fast.wistia.net", "fast.wistia.com"},
+		pattern: regexp.MustCompile(`^/embed/iframe/(\w+)/?$`),
+		embed:   "https://fast.wistia.net/embed/iframe/",
+	},
+	{
+		hosts:   []string{"loom.com", "www.loom.com"},
+		pattern: regexp.MustCompile(`^/(?:share|embed)/(\w+)/?$`),
+		embed:   "https://www.loom.com/embed/",
+	},
+}
+
+// embedVideoURL returns the embed URL of the video on the page at u if u is on the host of a known provider, or nil if
+// u is on such a host but is not the URL of a video. If the host is not known, it returns u as it is.
+func embedVideoURL(u *url.URL) *url.URL {
+
+	host := strings.ToLower(u.Hostname())
+	known := false
+
+	for _, p := range videoProviders {
+		if !containsFold(p.hosts, host) {
+			continue
+		}
+		known = true
+		m := p.pattern.FindStringSubmatch(u.Path)
+		if m == nil {
+			continue
+		}
+		q := u.Query()
+		id := m[1]
+		if id == "" { // a YouTube watch page
+			id = q.Get("v")
+		}
+		if id == "" {
+			continue
+		}
+		e, _ := url.Parse(p.embed + id)
+		if strings.HasPrefix(p.embed, "https://www.youtube") {
+			// The start time is given as t on watch pages but as start in embed URLs.
+			if t := strings.TrimSuffix(q.Get("t"), "s"); t != "" && strings.Trim(t, "0123456789") == "" {
+				e.RawQuery = "start=" + t
+			} else if s := q.Get("start"); s != "" && strings.Trim(s, "0123456789") == "" {
+				e.RawQuery = "start=" + s
+			}
+		}
+		return e
+	}
+
+	if known {
+		return nil
+	}
+	return u
+
+}
+
+// videoHostAllowed says if the videos of the host of u may be embedded.
+func (p *URLPolicy) videoHostAllowed(u *url.URL) bool {
+	hosts := p.VideoHosts
+	if hosts == nil {
+		hosts = DefaultVideoHosts
+	}
+	return containsFold(hosts, u.Hostname())
+}
+
+// containsFold says if list holds s, not minding case.
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// video
+type videoFormat struct {
+	src             string   // the embed URL, if the video may be embedded
+	link            string   // the URL of the link written instead of a video that may not be embedded
+	classes, styles []string // those of the line of the video
+}
+
+// newVideoFormat returns the format for the video embedded by o. A video whose URL is not on an allowed host is
+// written as a link to it, if the policy allows the link.
+func newVideoFormat(o *Op, p *URLPolicy) Formatter {
+	if src, ok := p.Resolve(o.Data, VideoURL); ok {
+		return &videoFormat{src: src}
+	}
+	link, _ := p.Resolve(o.Data, LinkURL)
+	return &videoFormat{link: link}
+}
+
+func (*videoFormat) Fmt() *Format { return nil } // The body contains the entire element.
+
+func (*videoFormat) HasFormat(o *Op) bool {
+	return o.Type == "video"
+}
+
+// videoFormat implements the FormatWriter interface.
+func (vf *videoFormat) Write(buf io.Writer) {
+	switch {
+	case vf.src != "":
+		io.WriteString(buf, `<iframe`+styleAttrs(append([]string{"ql-video"}, vf.classes...), vf.styles))
+		io.WriteString(buf, ` frameborder="0" allowfullscreen="true" src=`)
+		io.WriteString(buf, quoteAttr(vf.src))
+		io.WriteString(buf, ` sandbox="`+videoSandbox+`" allow="`+videoAllow+`"></iframe>`)
+	case vf.link != "":
+		io.WriteString(buf, "<a href=")
+		io.WriteString(buf, quoteAttr(vf.link))
+		io.WriteString(buf, ` target="_blank" rel="nofollow noopener">`)
+		textEscaper.WriteString(buf, vf.link)
+		io.WriteString(buf, "</a>")
+	}
+}
+
+// videoFormat implements the embedStyler interface.
+func (vf *videoFormat) setStyles(classes, styles []string) {
+	vf.classes, vf.styles = classes, styles
+}
+
+// videoFormat implements the BlockEmbed interface. A video that is embedded is a block of its own, but a link written
+// instead of a video stays in the line.
+func (vf *videoFormat) IsBlock() bool {
+	return vf.src != ""
+}
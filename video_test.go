@@ -0,0 +1,110 @@
+package quill
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestURLPolicy_Resolve_video(t *testing.T) {
+
+	cases := []struct {
+		policy URLPolicy
+		raw    string
+		want   string
+		ok     bool
+	}{
+		{URLPolicy{}, "https://www.youtube.com/watch?v=dQw4w9WgXcQ", "https://www.youtube.com/embed/dQw4w9WgXcQ", true},
+		{URLPolicy{}, "https://youtu.be/dQw4w9WgXcQ?t=42s", "https://www.youtube.com/embed/dQw4w9WgXcQ?start=42", true},
+		{URLPolicy{}, "https://m.youtube.com/shorts/abc-DEF_1", "https://www.youtube.com/embed/abc-DEF_1", true},
+		{URLPolicy{}, "https://www.youtube.com/embed/xyz?autoplay=1", "https://www.youtube.com/embed/xyz", true},
+		{URLPolicy{}, "https://vimeo.com/76979871", "https://player.vimeo.com/video/76979871", true},
+		{URLPolicy{}, "https://player.vimeo.com/video/76979871", "https://player.vimeo.com/video/76979871", true},
+		{URLPolicy{}, "https://www.dailymotion.com/video/x7tgad0", "https://www.dailymotion.com/embed/video/x7tgad0", true},
+		{URLPolicy{}, "https://dai.ly/x7tgad0", "https://www.dailymotion.com/embed/video/x7tgad0", true},
+		{URLPolicy{}, "https://www.loom.com/share/0281766fa2d04bb788eaf19e65135184", "https://www.loom.com/embed/0281766fa2d04bb788eaf19e65135184", true},
+		{URLPolicy{}, "https://www.youtube.com/feed/trending", "", false},
+		{URLPolicy{}, "https://videos.example.com/v/1", "", false},
+		{URLPolicy{}, "javascript:alert(1)", "", false},
+		{URLPolicy{}, "/videos/1", "", false},
+		{URLPolicy{VideoHosts: []string{"videos.example.com"}}, "https://videos.example.com/v/1", "https://videos.example.com/v/1", true},
+		{URLPolicy{VideoHosts: []string{"videos.example.com"}}, "https://youtu.be/dQw4w9WgXcQ", "", false},
+	}
+
+	for i, tc := range cases {
+		got, ok := tc.policy.Resolve(tc.raw, VideoURL)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("(index %d) resolving %q; got (%q, %v)", i, tc.raw, got, ok)
+		}
+	}
+
+}
+
+func TestVideo(t *testing.T) {
+
+	cases := map[string]struct {
+		opts []Option
+		ops  string
+		want string
+	}{
+		"youtube": {
+			ops: `[{"insert":{"video":"https://www.youtube.com/watch?v=dQw4w9WgXcQ"}},{"insert":"\n"}]`,
+			want: `<iframe class="ql-video" frameborder="0" allowfullscreen="true" src="https://www.youtube.com/embed/dQw4w9WgXcQ"` +
+				` sandbox="` + videoSandbox + `" allow="` + videoAllow + `"></iframe>`,
+		},
+		"other host": {
+			ops:  `[{"insert":{"video":"https://example.com/v?a=1&b=2"}},{"insert":"\n"}]`,
+			want: `<p><a href="https://example.com/v?a=1&amp;b=2" target="_blank" rel="nofollow noopener">https://example.com/v?a=1&amp;b=2</a></p>`,
+		},
+		"blocked": {
+			ops:  `[{"insert":"a"},{"insert":{"video":"javascript:alert(1)"}},{"insert":"b\n"}]`,
+			want: "<p>ab</p>",
+		},
+		"rewritten": {
+			opts: []Option{WithURLPolicy(&URLPolicy{Rewrite: func(u *url.URL, use URLUse) *url.URL {
+				if use == VideoURL && u.Host == "www.youtube.com" {
+					u.Host = "www.youtube-nocookie.com"
+				}
+				return u
+			}})},
+			ops: `[{"insert":{"video":"https://youtu.be/abc"}},{"insert":"\n"}]`,
+			want: `<iframe class="ql-video" frameborder="0" allowfullscreen="true" src="https://www.youtube-nocookie.com/embed/abc"` +
+				` sandbox="` + videoSandbox + `" allow="` + videoAllow + `"></iframe>`,
+		},
+	}
+
+	for k, tc := range cases {
+		t.Run(k, func(t *testing.T) {
+			got, err := NewRenderer(tc.opts...).Render([]byte(tc.ops))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("bad rendering\ngot:  %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+
+	// The HTML of videos can be imported back.
+	ops := `[{"insert":{"video":"https://player.vimeo.com/video/1"}},{"insert":"\n"}]`
+	out, err := Render([]byte(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := ImportHTML(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameJSON(t, imported, []byte(ops)) {
+		t.Errorf("bad import of %s; got: %s", out, imported)
+	}
+
+	// In plain text, a video is its URL.
+	text, err := RenderText([]byte(ops))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(text) != "https://player.vimeo.com/video/1\n" {
+		t.Errorf("bad text: %q", text)
+	}
+
+}